@@ -15,6 +15,7 @@ import (
 
 var bisectPort int
 var bisectConcurrency uint
+var bisectResume bool
 
 var bisectCmd = &cobra.Command{
 	Use:   "bisect job.yml [replicas]",
@@ -23,7 +24,10 @@ var bisectCmd = &cobra.Command{
 This command optionally takes in an additional value for the amount of replicas should be launched.
 If no value for this is specified, it defaults to one replica.
 
-Calling this command results in a RESTful HTTP server being created, with whose API the issue(s) can be bisected.`,
+Calling this command results in a RESTful HTTP server being created, with whose API the issue(s) can be bisected.
+
+The state of the bisection is written to a checkpoint file after every verdict.
+If biscepter was stopped before the bisection finished, the --resume flag continues it from there.`,
 	Args: cobra.RangeArgs(1, 2),
 	Run: func(cmd *cobra.Command, args []string) {
 		jobYaml, err := os.Open(args[0])
@@ -47,44 +51,11 @@ Calling this command results in a RESTful HTTP server being created, with whose
 		job.Log = logrus.StandardLogger()
 		job.MaxConcurrentReplicas = bisectConcurrency
 
-		// Handle interrupts
-		jobDoneChan := make(chan struct{})
-		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
-		defer stop()
-		go func() {
-			select {
-			case <-ctx.Done():
-				logrus.Infof("Captured an interrupt signal, commencing graceful shutdown of job. Interrupt again to force shutdown.")
-				stop()
-				gracefulShutdown(job)
-			case <-jobDoneChan:
-			}
-		}()
-
-		// Handle panics
-		defer func() {
-			if r := recover(); r != nil {
-				logrus.Errorf("Captured a panic: %v", r)
-				logrus.Errorf("Stack trace: %s", debug.Stack())
-				logrus.Infof("Attempting to gracefully shut down job")
-				gracefulShutdown(job)
-			}
-		}()
-
-		rsChan, ocChan, err := job.Run()
-		if err != nil {
-			logrus.Fatalf("Failed to start job - %v", err)
+		if bisectResume {
+			serveJob(job, job.Resume, bisectPort)
+		} else {
+			serveJob(job, job.Run, bisectPort)
 		}
-
-		serverType := server.HTTP
-		err = server.NewServer(serverType, bisectPort, rsChan, ocChan)
-		if err != nil {
-			logrus.Fatalf("Failed to start webserver - %v", err)
-		}
-
-		logrus.Infof("Job has finished, shutting down...")
-
-		jobDoneChan <- struct{}{}
 	},
 }
 
@@ -92,9 +63,52 @@ func init() {
 	rootCmd.AddCommand(bisectCmd)
 
 	bisectCmd.Flags().IntVarP(&bisectPort, "port", "p", 40032, "The port on which to start the server")
+	bisectCmd.Flags().BoolVar(&bisectResume, "resume", false, "Resume the job from its checkpoint file instead of starting the bisection from scratch")
 	bisectCmd.Flags().UintVarP(&bisectConcurrency, "max-concurrency", "c", 0, "The max amount of replicas that can run concurrently, or 0 if no limit")
 }
 
+// serveJob starts the passed job using the passed start function, e.g. [biscepter.Job.Run], and serves it via the HTTP API on the passed port until the server is stopped
+func serveJob(job *biscepter.Job, start func() (chan biscepter.RunningSystem, chan biscepter.OffendingCommit, error), port int) {
+	// Handle interrupts
+	jobDoneChan := make(chan struct{})
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	go func() {
+		select {
+		case <-ctx.Done():
+			logrus.Infof("Captured an interrupt signal, commencing graceful shutdown of job. Interrupt again to force shutdown.")
+			stop()
+			gracefulShutdown(job)
+		case <-jobDoneChan:
+		}
+	}()
+
+	// Handle panics
+	defer func() {
+		if r := recover(); r != nil {
+			logrus.Errorf("Captured a panic: %v", r)
+			logrus.Errorf("Stack trace: %s", debug.Stack())
+			logrus.Infof("Attempting to gracefully shut down job")
+			gracefulShutdown(job)
+		}
+	}()
+
+	rsChan, ocChan, err := start()
+	if err != nil {
+		logrus.Fatalf("Failed to start job - %v", err)
+	}
+
+	serverType := server.HTTP
+	err = server.NewServer(serverType, port, job, rsChan, ocChan)
+	if err != nil {
+		logrus.Fatalf("Failed to start webserver - %v", err)
+	}
+
+	logrus.Infof("Job has finished, shutting down...")
+
+	jobDoneChan <- struct{}{}
+}
+
 func gracefulShutdown(job *biscepter.Job) {
 	if err := job.Stop(); err != nil {
 		logrus.Errorf("Failed to gracefully shut down job - %v", err)
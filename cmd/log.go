@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"os"
+	"strconv"
+
+	"github.com/DominicWuest/biscepter/pkg/biscepter"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var logCheckpoint string
+
+var logCmd = &cobra.Command{
+	Use:   "log job.yml [replica]",
+	Short: "Print the verdicts of a replica in the format of git bisect log",
+	Long: `Print the verdicts of a replica in the format of git bisect log.
+This command optionally takes in the index of the replica whose verdicts should be printed.
+If no value for this is specified, it defaults to the first replica.
+
+The verdicts are read from the checkpoint file of the job, so this works both for running and stopped bisections.
+Each verdict is preceded by a comment holding its timestamp and notes.
+The printed log can be replayed using git bisect replay, or used to seed a new job using the replay command.`,
+	Args: cobra.RangeArgs(1, 2),
+	Run: func(cmd *cobra.Command, args []string) {
+		jobYaml, err := os.Open(args[0])
+		if err != nil {
+			logrus.Fatalf("Failed to open job yaml - %v", err)
+		}
+		job, err := biscepter.GetJobFromConfig(jobYaml)
+		if err != nil {
+			logrus.Fatalf("Failed to read job config from yaml - %v", err)
+		}
+
+		replica := 0
+		if len(args) == 2 {
+			var err error
+			replica, err = strconv.Atoi(args[1])
+			if err != nil {
+				logrus.Fatalf("%s not a valid argument for replica index", args[1])
+			}
+		}
+		job.Checkpoint = logCheckpoint
+
+		if err := job.WriteBisectLog(os.Stdout, replica); err != nil {
+			logrus.Fatalf("Failed to write bisect log - %v", err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(logCmd)
+
+	logCmd.Flags().StringVar(&logCheckpoint, "checkpoint", ".biscepter-checkpoint~", "The checkpoint file of the job")
+}
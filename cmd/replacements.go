@@ -0,0 +1,223 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/DominicWuest/biscepter/pkg/biscepter"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var replacementsFile string
+var replacementsJob string
+var replacementsRepository string
+var replacementsDockerfileHash string
+var replacementsForgetAll bool
+
+var replacementsCmd = &cobra.Command{
+	Use:   "replacements",
+	Short: "Manage the commits which are avoided because they broke the build",
+	Long: `Manage the commits which are avoided because they broke the build.
+Whenever the build of a commit fails, or its system fails the healthchecks, the commit is replaced by the closest commit of the remaining window and the replacement is stored.
+If no commit of the window is left to replace it with, the commit is skipped instead.
+Every entry is scoped to the repository and dockerfile of the job which recorded it, and states why the commit broke the build.
+Entries written by older versions of biscepter aren't scoped, and apply to every job.
+
+The entries of a single scope can be selected using either the --job flag, or the --repository and --dockerfile-hash flags.
+Since entries which aren't scoped apply to every job, they are selected as well.
+The store shouldn't be modified while a job using it is running.`,
+}
+
+var replacementsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the stored commit replacements",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		entries := filterReplacements(readReplacements())
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "COMMIT\tREPLACEMENT\tREPOSITORY\tDOCKERFILE\tRECORDED\tKIND\tREASON")
+		for _, entry := range entries {
+			repository, dockerfileHash, timestamp, kind := "-", "-", "-", "-"
+			if entry.IsScoped() {
+				repository, dockerfileHash = entry.Repository, entry.DockerfileHash[:min(12, len(entry.DockerfileHash))]
+			}
+			if !entry.Timestamp.IsZero() {
+				timestamp = entry.Timestamp.Format(time.RFC3339)
+			}
+			if entry.Kind != "" {
+				kind = string(entry.Kind)
+			}
+			reason, _, _ := strings.Cut(entry.Reason, "\n")
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", entry.Commit, entry.Replacement, repository, dockerfileHash, timestamp, kind, reason)
+		}
+		w.Flush()
+	},
+}
+
+var replacementsForgetCmd = &cobra.Command{
+	Use:   "forget [commits...] [--all]",
+	Short: "Remove stored commit replacements, e.g. if they were recorded wrongly",
+	Long: `Remove stored commit replacements, e.g. if they were recorded wrongly.
+The passed commits may be abbreviated. If --all is set, all selected entries are removed instead.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) == 0 && !replacementsForgetAll {
+			logrus.Fatalf("No commits to forget specified, pass them as arguments or set --all")
+		}
+
+		entries := readReplacements()
+		selected := filterReplacements(entries)
+		kept := []biscepter.CommitReplacement{}
+		for _, entry := range entries {
+			if !isSelected(entry, selected) || !(replacementsForgetAll || matchesCommit(entry, args)) {
+				kept = append(kept, entry)
+			}
+		}
+
+		if err := biscepter.WriteCommitReplacements(replacementsFile, kept); err != nil {
+			logrus.Fatalf("Failed to write replacements - %v", err)
+		}
+		fmt.Printf("Forgot %d replacements\n", len(entries)-len(kept))
+	},
+}
+
+var replacementsImportCmd = &cobra.Command{
+	Use:   "import replacements",
+	Short: "Add the commit replacements of another store, e.g. one exported on another machine",
+	Long: `Add the commit replacements of another store, e.g. one exported on another machine.
+Entries which aren't scoped, such as those written by older versions of biscepter, are scoped to the selected repository and dockerfile if set.
+Entries which are already stored are skipped.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		imported, err := biscepter.ReadCommitReplacements(args[0])
+		if err != nil {
+			logrus.Fatalf("Failed to read replacements to import - %v", err)
+		}
+
+		repository, dockerfileHash := getReplacementsScope()
+		entries := readReplacements()
+		added := 0
+		for _, entry := range imported {
+			if !entry.IsScoped() {
+				entry.Repository, entry.DockerfileHash = repository, dockerfileHash
+			}
+			if isSelected(entry, entries) {
+				continue
+			}
+			entries = append(entries, entry)
+			added++
+		}
+
+		if err := biscepter.WriteCommitReplacements(replacementsFile, entries); err != nil {
+			logrus.Fatalf("Failed to write replacements - %v", err)
+		}
+		fmt.Printf("Imported %d replacements\n", added)
+	},
+}
+
+var replacementsExportCmd = &cobra.Command{
+	Use:   "export [file]",
+	Short: "Write the selected commit replacements to a file, or stdout if none is passed",
+	Args:  cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		entries := filterReplacements(readReplacements())
+
+		if len(args) == 0 {
+			if err := biscepter.EncodeCommitReplacements(os.Stdout, entries); err != nil {
+				logrus.Fatalf("Failed to export replacements - %v", err)
+			}
+			return
+		}
+		if err := biscepter.WriteCommitReplacements(args[0], entries); err != nil {
+			logrus.Fatalf("Failed to export replacements - %v", err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(replacementsCmd)
+	replacementsCmd.AddCommand(replacementsListCmd, replacementsForgetCmd, replacementsImportCmd, replacementsExportCmd)
+
+	replacementsCmd.PersistentFlags().StringVar(&replacementsFile, "file", ".biscepter-replacements~", "The file the commit replacements are stored in")
+	replacementsCmd.PersistentFlags().StringVar(&replacementsJob, "job", "", "Only select the entries of the repository and dockerfile of this job.yml, and those which aren't scoped")
+	replacementsCmd.PersistentFlags().StringVar(&replacementsRepository, "repository", "", "Only select the entries of this repository URL, and those which aren't scoped")
+	replacementsCmd.PersistentFlags().StringVar(&replacementsDockerfileHash, "dockerfile-hash", "", "Only select the entries of the dockerfile with this hash, and those which aren't scoped")
+	replacementsCmd.MarkFlagsMutuallyExclusive("job", "repository")
+	replacementsCmd.MarkFlagsMutuallyExclusive("job", "dockerfile-hash")
+
+	replacementsForgetCmd.Flags().BoolVar(&replacementsForgetAll, "all", false, "Forget all selected entries")
+}
+
+// readReplacements reads in all entries of the commit replacements store
+func readReplacements() []biscepter.CommitReplacement {
+	entries, err := biscepter.ReadCommitReplacements(replacementsFile)
+	if err != nil {
+		logrus.Fatalf("Failed to read replacements - %v", err)
+	}
+	return entries
+}
+
+// getReplacementsScope returns the repository and dockerfile hash selected by the flags, where empty values select every repository or dockerfile
+func getReplacementsScope() (string, string) {
+	if replacementsJob == "" {
+		return replacementsRepository, replacementsDockerfileHash
+	}
+
+	jobYaml, err := os.Open(replacementsJob)
+	if err != nil {
+		logrus.Fatalf("Failed to open job yaml - %v", err)
+	}
+	job, err := biscepter.GetJobFromConfig(jobYaml)
+	if err != nil {
+		logrus.Fatalf("Failed to read job config from yaml - %v", err)
+	}
+	dockerfileHash, err := job.DockerfileHash()
+	if err != nil {
+		logrus.Fatalf("Failed to read dockerfile of job - %v", err)
+	}
+	return job.Repository, dockerfileHash
+}
+
+// filterReplacements returns the passed entries which apply to the scope selected by the flags, including the entries which aren't scoped
+func filterReplacements(entries []biscepter.CommitReplacement) []biscepter.CommitReplacement {
+	repository, dockerfileHash := getReplacementsScope()
+	filtered := []biscepter.CommitReplacement{}
+	for _, entry := range entries {
+		// Empty values select every repository or dockerfile, so the entry's own value is used in that case
+		scopeRepository, scopeDockerfileHash := repository, dockerfileHash
+		if scopeRepository == "" {
+			scopeRepository = entry.Repository
+		}
+		if scopeDockerfileHash == "" {
+			scopeDockerfileHash = entry.DockerfileHash
+		}
+		if entry.AppliesTo(scopeRepository, scopeDockerfileHash) {
+			filtered = append(filtered, entry)
+		}
+	}
+	return filtered
+}
+
+// isSelected returns whether the passed entry is one of the passed entries, ignoring its reason, log and timestamp
+func isSelected(entry biscepter.CommitReplacement, entries []biscepter.CommitReplacement) bool {
+	for _, e := range entries {
+		if e.Commit == entry.Commit && e.Replacement == entry.Replacement && e.Repository == entry.Repository && e.DockerfileHash == entry.DockerfileHash {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesCommit returns whether the commit of the passed entry is one of the passed, possibly abbreviated, commits
+func matchesCommit(entry biscepter.CommitReplacement, commits []string) bool {
+	for _, commit := range commits {
+		if strings.HasPrefix(entry.Commit, commit) {
+			return true
+		}
+	}
+	return false
+}
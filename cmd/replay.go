@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"os"
+	"strconv"
+
+	"github.com/DominicWuest/biscepter/pkg/biscepter"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var replayPort int
+var replayConcurrency uint
+
+var replayCmd = &cobra.Command{
+	Use:   "replay log.txt job.yml [replicas]",
+	Short: "Start a server for bisecting an issue based on a job.yml, continuing from a git bisect log",
+	Long: `Start a server for bisecting an issue based on a job.yml, continuing from a git bisect log.
+This command optionally takes in an additional value for the amount of replicas should be launched.
+If no value for this is specified, it defaults to one replica.
+
+The log has to be in the format written by git bisect log or the log command.
+Every replica starts with the verdicts of the log applied, after which the bisection continues like for the bisect command.
+Terms other than good and bad are supported if they were set in the log using git bisect start --term-old and --term-new.`,
+	Args: cobra.RangeArgs(2, 3),
+	Run: func(cmd *cobra.Command, args []string) {
+		jobYaml, err := os.Open(args[1])
+		if err != nil {
+			logrus.Fatalf("Failed to open job yaml - %v", err)
+		}
+		job, err := biscepter.GetJobFromConfig(jobYaml)
+		if err != nil {
+			logrus.Fatalf("Failed to read job config from yaml - %v", err)
+		}
+
+		bisectLog, err := os.Open(args[0])
+		if err != nil {
+			logrus.Fatalf("Failed to open bisect log - %v", err)
+		}
+		verdicts, err := job.ParseBisectLog(bisectLog)
+		bisectLog.Close()
+		if err != nil {
+			logrus.Fatalf("Failed to parse bisect log - %v", err)
+		}
+
+		replicas := 1
+		if len(args) == 3 {
+			var err error
+			replicas, err = strconv.Atoi(args[2])
+			if err != nil {
+				logrus.Fatalf("%s not a valid argument for amount of replicas", args[2])
+			}
+		}
+		job.ReplicasCount = replicas
+		job.Log = logrus.StandardLogger()
+		job.MaxConcurrentReplicas = replayConcurrency
+
+		job.KnownVerdicts = make([][]biscepter.VerdictRecord, replicas)
+		for i := range job.KnownVerdicts {
+			job.KnownVerdicts[i] = verdicts
+		}
+
+		serveJob(job, job.Run, replayPort)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(replayCmd)
+
+	replayCmd.Flags().IntVarP(&replayPort, "port", "p", 40032, "The port on which to start the server")
+	replayCmd.Flags().UintVarP(&replayConcurrency, "max-concurrency", "c", 0, "The max amount of replicas that can run concurrently, or 0 if no limit")
+}
@@ -0,0 +1,210 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"runtime/debug"
+	"sort"
+	"strconv"
+
+	"github.com/DominicWuest/biscepter/pkg/biscepter"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var runScript string
+var runConcurrency uint
+var runResume bool
+
+var runCmd = &cobra.Command{
+	Use:   "run job.yml [replicas] --script verdict.sh",
+	Short: "Bisect an issue automatically based on a job.yml and a verdict script",
+	Long: `Bisect an issue automatically based on a job.yml and a verdict script.
+This command optionally takes in an additional value for the amount of replicas should be launched.
+If no value for this is specified, it defaults to one replica.
+
+The verdict script is run on the host for every system that is ready to be tested.
+Within the script, the environment variable $PORT<XXXX> can be used to get the port to which <XXXX> was mapped to on the host (e.g. $PORT443).
+Additionally, $COMMIT holds the hash of the commit under test and $REPLICA_INDEX the index of the system's replica.
+
+Like for git bisect run, the exit code of the script determines the verdict:
+an exit code of 0 means good, 125 means skip and all other codes from 1 to 127 mean bad.
+For reversed jobs, good and bad still refer to the good and bad commit of the job config, i.e. the fixed and broken behaviour.
+Any other exit code aborts the bisection.
+The verdict script may be omitted if the job rates its systems using a verdict command, or if it bisects in build or startup mode.
+
+Once every replica has found its offending commit, a report is printed and the command exits.
+Like for the bisect command, an interrupted bisection can be continued from its checkpoint file using the --resume flag.`,
+	Args: cobra.RangeArgs(1, 2),
+	Run: func(cmd *cobra.Command, args []string) {
+		jobYaml, err := os.Open(args[0])
+		if err != nil {
+			logrus.Fatalf("Failed to open job yaml - %v", err)
+		}
+		job, err := biscepter.GetJobFromConfig(jobYaml)
+		if err != nil {
+			logrus.Fatalf("Failed to read job config from yaml - %v", err)
+		}
+
+		// Only systems which aren't rated by biscepter itself are rated by the verdict script
+		if runScript == "" && job.VerdictCommand == "" && job.Mode == biscepter.RunMode {
+			logrus.Fatalf("No verdict script specified, which is required unless the job has a verdict command or bisects in build or startup mode")
+		}
+		script, err := filepath.Abs(runScript)
+		if err != nil {
+			logrus.Fatalf("Failed to get path of verdict script - %v", err)
+		}
+
+		replicas := 1
+		if len(args) == 2 {
+			var err error
+			replicas, err = strconv.Atoi(args[1])
+			if err != nil {
+				logrus.Fatalf("%s not a valid argument for amount of replicas", args[1])
+			}
+		}
+		job.ReplicasCount = replicas
+		job.Log = logrus.StandardLogger()
+		job.MaxConcurrentReplicas = runConcurrency
+
+		// Handle interrupts
+		jobDoneChan := make(chan struct{})
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+		go func() {
+			select {
+			case <-ctx.Done():
+				logrus.Infof("Captured an interrupt signal, commencing graceful shutdown of job. Interrupt again to force shutdown.")
+				stop()
+				gracefulShutdown(job)
+			case <-jobDoneChan:
+			}
+		}()
+
+		// Handle panics
+		defer func() {
+			if r := recover(); r != nil {
+				logrus.Errorf("Captured a panic: %v", r)
+				logrus.Errorf("Stack trace: %s", debug.Stack())
+				logrus.Infof("Attempting to gracefully shut down job")
+				gracefulShutdown(job)
+			}
+		}()
+
+		var rsChan chan biscepter.RunningSystem
+		var ocChan chan biscepter.OffendingCommit
+		if runResume {
+			rsChan, ocChan, err = job.Resume()
+		} else {
+			rsChan, ocChan, err = job.Run()
+		}
+		if err != nil {
+			logrus.Fatalf("Failed to start job - %v", err)
+		}
+
+		// Channel over which the verdict scripts report failures which should abort the bisection
+		errChan := make(chan error)
+
+		offendingCommits := []biscepter.OffendingCommit{}
+		for len(offendingCommits) < job.ReplicasCount {
+			select {
+			case commit := <-ocChan:
+				logrus.Infof("Replica %d found offending commit %s", commit.ReplicaIndex, commit.Commit)
+				offendingCommits = append(offendingCommits, commit)
+			case system := <-rsChan:
+				go func(system biscepter.RunningSystem) {
+					verdict, err := runVerdictScript(script, system)
+					if err != nil {
+						errChan <- err
+						return
+					}
+					logrus.Infof("Verdict script rated commit %s of replica %d as %s", system.Commit, system.ReplicaIndex, job.TermOfVerdict(verdict))
+					system.Rate(verdict)
+				}(system)
+			case err := <-errChan:
+				logrus.Errorf("Aborting bisection - %v", err)
+				gracefulShutdown(job)
+			}
+		}
+
+		jobDoneChan <- struct{}{}
+
+		// Print the report
+		sort.Slice(offendingCommits, func(i, j int) bool {
+			return offendingCommits[i].ReplicaIndex < offendingCommits[j].ReplicaIndex
+		})
+		for _, commit := range offendingCommits {
+			fmt.Printf("Replica %d: first %s commit %s\n", commit.ReplicaIndex, commit.Term, commit.Commit)
+			fmt.Printf("\tAuthor: %s\n", commit.CommitAuthor)
+			fmt.Printf("\tDate: %s\n", commit.CommitDate)
+			fmt.Printf("\tMessage: %s\n", commit.CommitMessage)
+			for _, step := range commit.MergePath {
+				fmt.Printf("\tVia merge %s of %s\n", step.MergeCommit, step.MergedParent)
+			}
+			if len(commit.MergedCommits) != 0 {
+				fmt.Printf("\tMerged commits: %v\n", commit.MergedCommits)
+			}
+			if len(commit.PossibleOtherCommits) != 0 {
+				fmt.Printf("\tPossible other commits: %v\n", commit.PossibleOtherCommits)
+			}
+			if commit.AmbiguousRange != nil {
+				fmt.Printf("\tAmbiguous range: %s..%s (%d commits)\n", commit.AmbiguousRange.Older, commit.AmbiguousRange.Newer, len(commit.AmbiguousRange.Commits))
+			}
+			fmt.Printf("\tStatus: %s\n", commit.Status)
+			if commit.Restarts != 0 {
+				fmt.Printf("\tRestarts: %d\n", commit.Restarts)
+			}
+			for _, inconsistency := range commit.Inconsistencies {
+				fmt.Printf("\tInconsistency: %s\n", inconsistency)
+			}
+			if commit.HealthcheckError != "" {
+				fmt.Printf("\tHealthcheck error: %s\n", commit.HealthcheckError)
+				fmt.Printf("\tContainer logs:\n%s\n", commit.ContainerLogs)
+			}
+		}
+
+		logrus.Infof("Job has finished, shutting down...")
+		if err := job.Stop(); err != nil {
+			logrus.Fatalf("Failed to stop job - %v", err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(runCmd)
+
+	runCmd.Flags().StringVarP(&runScript, "script", "s", "", "The path to the verdict script which is run for every system to test")
+	runCmd.Flags().BoolVar(&runResume, "resume", false, "Resume the job from its checkpoint file instead of starting the bisection from scratch")
+	runCmd.Flags().UintVarP(&runConcurrency, "max-concurrency", "c", 0, "The max amount of replicas that can run concurrently, or 0 if no limit")
+}
+
+// runVerdictScript runs the passed verdict script against the passed running system and returns the verdict its exit code results in
+func runVerdictScript(script string, system biscepter.RunningSystem) (biscepter.Verdict, error) {
+	cmd := exec.Command(script)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	// Set the ports mapping env variables, as well as the commit and replica index
+	cmd.Env = os.Environ()
+	for k, v := range system.Ports {
+		cmd.Env = append(cmd.Env, fmt.Sprintf("PORT%d=%d", k, v))
+	}
+	cmd.Env = append(cmd.Env, fmt.Sprintf("COMMIT=%s", system.Commit), fmt.Sprintf("REPLICA_INDEX=%d", system.ReplicaIndex))
+
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	if err != nil && !errors.As(err, &exitErr) {
+		return 0, fmt.Errorf("failed to run verdict script %s - %v", script, err)
+	}
+
+	verdict, err := biscepter.VerdictFromExitCode(cmd.ProcessState.ExitCode())
+	if err != nil {
+		return 0, fmt.Errorf("verdict script %s failed for commit %s of replica %d - %v", script, system.Commit, system.ReplicaIndex, err)
+	}
+	return verdict, nil
+}
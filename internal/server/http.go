@@ -5,6 +5,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/DominicWuest/biscepter/pkg/biscepter"
 	"github.com/dchest/uniuri"
@@ -12,6 +15,8 @@ import (
 )
 
 type httpServer struct {
+	job *biscepter.Job
+
 	rsChan chan biscepter.RunningSystem
 	ocChan chan biscepter.OffendingCommit
 
@@ -21,7 +26,8 @@ type httpServer struct {
 	exitChan chan struct{}
 }
 
-func (h *httpServer) init(port int, rsChan chan biscepter.RunningSystem, ocChan chan biscepter.OffendingCommit) error {
+func (h *httpServer) init(port int, job *biscepter.Job, rsChan chan biscepter.RunningSystem, ocChan chan biscepter.OffendingCommit) error {
+	h.job = job
 	h.rsChan = rsChan
 	h.ocChan = ocChan
 
@@ -34,6 +40,18 @@ func (h *httpServer) init(port int, rsChan chan biscepter.RunningSystem, ocChan
 	router.GET("/system", h.getSystem)
 	router.POST("/isGood/:systemId", h.postIsGood)
 	router.POST("/isBad/:systemId", h.postIsBad)
+	router.POST("/isSkip/:systemId", h.postIsSkip)
+	// Register the job's custom terms, e.g. /isFixed/:systemId for the term "fixed"
+	for _, term := range []string{job.TermOld, job.TermNew} {
+		if strings.EqualFold(term, "good") || strings.EqualFold(term, "bad") {
+			continue
+		}
+		router.POST(fmt.Sprintf("/is%s%s/:systemId", strings.ToUpper(term[:1]), term[1:]), h.postIsTerm(term))
+	}
+	router.POST("/replicas", h.postReplicas)
+	router.DELETE("/replicas/:replicaIndex", h.deleteReplica)
+	router.POST("/replicas/:replicaIndex/mark", h.postMarkCommit)
+	router.POST("/replicas/:replicaIndex/undo", h.postUndoVerdict)
 	router.POST("/stop", h.stop)
 
 	httpSrv := &http.Server{
@@ -56,29 +74,118 @@ type runningSystemResponse struct {
 	Ports map[string]string `json:"ports"`
 }
 
+type replicaResponse struct {
+	ReplicaIndex int `json:"replicaIndex"`
+}
+
 type offendingCommitResponse struct {
 	ReplicaIndex int `json:"replicaIndex"`
 
+	Cancelled bool `json:"cancelled"`
+
 	Commit       string `json:"commit"`
 	CommitOffset int    `json:"commitOffset"`
+	Term         string `json:"term"`
 
 	CommitMessage string `json:"commitMessage"`
 	CommitDate    string `json:"commitDate"`
 	CommitAuthor  string `json:"commitAuthor"`
+
+	Confidence float64 `json:"confidence"`
+
+	Verdicts []verdictRecordResponse `json:"verdicts"`
+
+	MergePath     []mergeStepResponse `json:"mergePath"`
+	MergedCommits []string            `json:"mergedCommits"`
+
+	Status          string   `json:"status"`
+	Inconsistencies []string `json:"inconsistencies"`
+	Restarts        int      `json:"restarts"`
+
+	HealthcheckError string `json:"healthcheckError"`
+	ContainerLogs    string `json:"containerLogs"`
+
+	AmbiguousRange *commitRangeResponse `json:"ambiguousRange,omitempty"`
+}
+
+type commitRangeResponse struct {
+	Older   string   `json:"older"`
+	Newer   string   `json:"newer"`
+	Commits []string `json:"commits"`
+}
+
+type mergeStepResponse struct {
+	MergeCommit  string `json:"mergeCommit"`
+	MergedParent string `json:"mergedParent"`
+}
+
+type verdictRecordResponse struct {
+	Commit    string `json:"commit"`
+	Verdict   string `json:"verdict"`
+	Output    string `json:"output"`
+	Timestamp string `json:"timestamp"`
+	Notes     string `json:"notes"`
 }
 
 func (h *httpServer) getSystem(c *gin.Context) {
 	select {
 	case commit := <-h.ocChan:
+		verdicts := []verdictRecordResponse{}
+		for _, verdict := range commit.Verdicts {
+			verdicts = append(verdicts, verdictRecordResponse{
+				Commit:    verdict.Commit,
+				Verdict:   verdict.Verdict.String(),
+				Output:    verdict.Output,
+				Timestamp: verdict.Timestamp.Format(time.RFC3339),
+				Notes:     verdict.Notes,
+			})
+		}
+
+		mergePath := []mergeStepResponse{}
+		for _, step := range commit.MergePath {
+			mergePath = append(mergePath, mergeStepResponse{
+				MergeCommit:  step.MergeCommit,
+				MergedParent: step.MergedParent,
+			})
+		}
+
+		var ambiguousRange *commitRangeResponse
+		if commit.AmbiguousRange != nil {
+			ambiguousRange = &commitRangeResponse{
+				Older:   commit.AmbiguousRange.Older,
+				Newer:   commit.AmbiguousRange.Newer,
+				Commits: append([]string{}, commit.AmbiguousRange.Commits...),
+			}
+		}
+
 		c.JSON(http.StatusOK, offendingCommitResponse{
 			ReplicaIndex: commit.ReplicaIndex,
 
+			Cancelled: commit.Cancelled,
+
 			Commit:       commit.Commit,
 			CommitOffset: commit.CommitOffset,
+			Term:         commit.Term,
 
 			CommitMessage: commit.CommitMessage,
 			CommitDate:    commit.CommitDate,
 			CommitAuthor:  commit.CommitAuthor,
+
+			Confidence: commit.Confidence,
+
+			Verdicts: verdicts,
+
+			MergePath:     mergePath,
+			MergedCommits: append([]string{}, commit.MergedCommits...),
+
+			Status:          commit.Status.String(),
+			Inconsistencies: append([]string{}, commit.Inconsistencies...),
+			Restarts:        commit.Restarts,
+
+			HealthcheckError: commit.HealthcheckError,
+			ContainerLogs:    commit.ContainerLogs,
+
+			AmbiguousRange: ambiguousRange,
 		})
 	case system := <-h.rsChan:
 		// Register ID
@@ -113,6 +220,7 @@ func (h *httpServer) getSystem(c *gin.Context) {
 func (h *httpServer) postIsGood(c *gin.Context) {
 	id := c.Param("systemId")
 	if rs, found := h.rsMap[id]; found {
+		rs.Notes = c.Query("notes")
 		rs.IsGood()
 		delete(h.rsMap, id)
 		c.AbortWithStatus(200)
@@ -124,6 +232,7 @@ func (h *httpServer) postIsGood(c *gin.Context) {
 func (h *httpServer) postIsBad(c *gin.Context) {
 	id := c.Param("systemId")
 	if rs, found := h.rsMap[id]; found {
+		rs.Notes = c.Query("notes")
 		rs.IsBad()
 		delete(h.rsMap, id)
 		c.AbortWithStatus(200)
@@ -132,6 +241,103 @@ func (h *httpServer) postIsBad(c *gin.Context) {
 	}
 }
 
+func (h *httpServer) postIsSkip(c *gin.Context) {
+	id := c.Param("systemId")
+	if rs, found := h.rsMap[id]; found {
+		rs.Notes = c.Query("notes")
+		rs.IsSkip()
+		delete(h.rsMap, id)
+		c.AbortWithStatus(200)
+	} else {
+		c.AbortWithStatus(404)
+	}
+}
+
+func (h *httpServer) postIsTerm(term string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.Param("systemId")
+		if rs, found := h.rsMap[id]; found {
+			rs.Notes = c.Query("notes")
+			if err := rs.IsTerm(term); err != nil {
+				c.AbortWithStatus(500)
+				return
+			}
+			delete(h.rsMap, id)
+			c.AbortWithStatus(200)
+		} else {
+			c.AbortWithStatus(404)
+		}
+	}
+}
+
+func (h *httpServer) postReplicas(c *gin.Context) {
+	index, err := h.job.AddReplica()
+	if err != nil {
+		c.AbortWithStatus(500)
+		return
+	}
+	c.JSON(http.StatusCreated, replicaResponse{
+		ReplicaIndex: index,
+	})
+}
+
+func (h *httpServer) deleteReplica(c *gin.Context) {
+	index, err := strconv.Atoi(c.Param("replicaIndex"))
+	if err != nil {
+		c.AbortWithStatus(400)
+		return
+	}
+	if err := h.job.CancelReplica(index); err != nil {
+		c.AbortWithStatus(500)
+		return
+	}
+	c.AbortWithStatus(200)
+}
+
+func (h *httpServer) postMarkCommit(c *gin.Context) {
+	index, err := strconv.Atoi(c.Param("replicaIndex"))
+	if err != nil {
+		c.AbortWithStatus(400)
+		return
+	}
+	commit := c.Query("commit")
+	if commit == "" {
+		c.AbortWithStatus(400)
+		return
+	}
+	// Accept both the job's terms and plain verdicts
+	verdict, err := h.job.VerdictOfTerm(c.Query("verdict"))
+	if err != nil {
+		if err := verdict.UnmarshalText([]byte(c.Query("verdict"))); err != nil {
+			c.AbortWithStatus(400)
+			return
+		}
+	}
+	if err := h.job.MarkCommit(index, commit, verdict); err != nil {
+		c.AbortWithStatus(500)
+		return
+	}
+	c.AbortWithStatus(200)
+}
+
+func (h *httpServer) postUndoVerdict(c *gin.Context) {
+	index, err := strconv.Atoi(c.Param("replicaIndex"))
+	if err != nil {
+		c.AbortWithStatus(400)
+		return
+	}
+	steps, err := strconv.Atoi(c.DefaultQuery("steps", "1"))
+	if err != nil {
+		c.AbortWithStatus(400)
+		return
+	}
+	if err := h.job.UndoVerdict(index, steps); err != nil {
+		c.AbortWithStatus(500)
+		return
+	}
+	c.AbortWithStatus(200)
+}
+
 func (h *httpServer) stop(c *gin.Context) {
 	c.AbortWithStatus(200)
 	h.exitChan <- struct{}{}
@@ -13,14 +13,14 @@ const (
 )
 
 type Server interface {
-	init(int, chan biscepter.RunningSystem, chan biscepter.OffendingCommit) error
+	init(int, *biscepter.Job, chan biscepter.RunningSystem, chan biscepter.OffendingCommit) error
 }
 
-func NewServer(serverType ServerType, port int, rsChan chan biscepter.RunningSystem, ocChan chan biscepter.OffendingCommit) error {
+func NewServer(serverType ServerType, port int, job *biscepter.Job, rsChan chan biscepter.RunningSystem, ocChan chan biscepter.OffendingCommit) error {
 	switch serverType {
 	case HTTP:
 		var server Server = &httpServer{}
-		return server.init(port, rsChan, ocChan)
+		return server.init(port, job, rsChan, ocChan)
 	}
 	return fmt.Errorf("%d is not a valid server type", serverType)
 }
@@ -0,0 +1,218 @@
+package biscepter
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// reservedGitTerms are terms which git bisect doesn't accept as custom terms
+var reservedGitTerms = []string{"help", "start", "skip", "next", "reset", "visualize", "view", "replay", "log", "run", "terms"}
+
+// knownVerdictsYaml holds the known verdicts of a single replica, read from a git bisect log and listed by verdict
+type knownVerdictsYaml struct {
+	BisectLog string `yaml:"bisectLog"`
+
+	Good []string `yaml:"good"`
+	Bad  []string `yaml:"bad"`
+	Skip []string `yaml:"skip"`
+}
+
+// toVerdictRecords converts the yaml known verdicts to the verdicts of a replica of the passed job.
+// The verdicts of the bisect log come first, followed by the listed good, bad and skipped commits.
+func (k knownVerdictsYaml) toVerdictRecords(job *Job) ([]VerdictRecord, error) {
+	verdicts := []VerdictRecord{}
+	if k.BisectLog != "" {
+		bisectLog, err := os.Open(k.BisectLog)
+		if err != nil {
+			return nil, errors.Join(fmt.Errorf("couldn't open bisect log %s", k.BisectLog), err)
+		}
+		defer bisectLog.Close()
+		verdicts, err = job.ParseBisectLog(bisectLog)
+		if err != nil {
+			return nil, errors.Join(fmt.Errorf("couldn't parse bisect log %s", k.BisectLog), err)
+		}
+	}
+
+	for verdict, commits := range [][]string{Good: k.Good, Bad: k.Bad, Skip: k.Skip} {
+		for _, commit := range commits {
+			verdicts = append(verdicts, VerdictRecord{
+				Commit:  commit,
+				Verdict: Verdict(verdict),
+			})
+		}
+	}
+	return verdicts, nil
+}
+
+// ParseBisectLog reads a log in the format written by git bisect log, as consumed by git bisect replay, and returns the verdicts it contains in order.
+// Besides good, bad and skip, the old and new commands as well as custom terms set via git bisect start --term-old and --term-new are supported.
+// The commits passed to git bisect start are included as verdicts as well.
+// Since the log's terms refer to the behaviour of old and new commits, they are mapped to verdicts using the job's Reverse setting, i.e. old means good unless the job is reversed.
+func (j *Job) ParseBisectLog(r io.Reader) ([]VerdictRecord, error) {
+	termOld, termNew := "good", "bad"
+
+	verdicts := []VerdictRecord{}
+	addVerdicts := func(verdict Verdict, commits []string) {
+		for _, commit := range commits {
+			verdicts = append(verdicts, VerdictRecord{
+				Commit:  commit,
+				Verdict: verdict,
+			})
+		}
+	}
+
+	scanner := bufio.NewScanner(r)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		for i := range fields {
+			fields[i] = strings.Trim(fields[i], "'")
+		}
+		if len(fields) < 3 || fields[0] != "git" || fields[1] != "bisect" {
+			return nil, fmt.Errorf("line %d is no git bisect command: %q", lineNumber, line)
+		}
+		command, args := fields[2], fields[3:]
+
+		switch command {
+		case "start":
+			revs := []string{}
+			for i := 0; i < len(args); i++ {
+				arg := args[i]
+				if arg == "--" {
+					// Only paths follow
+					break
+				}
+				option, value, hasValue := strings.Cut(arg, "=")
+				switch option {
+				case "--term-old", "--term-good", "--term-new", "--term-bad":
+					if !hasValue {
+						if i+1 >= len(args) {
+							return nil, fmt.Errorf("line %d is missing the value of %s", lineNumber, option)
+						}
+						i++
+						value = args[i]
+					}
+					if option == "--term-old" || option == "--term-good" {
+						termOld = value
+					} else {
+						termNew = value
+					}
+				default:
+					if !strings.HasPrefix(arg, "--") {
+						revs = append(revs, arg)
+					}
+				}
+			}
+			if len(revs) != 0 {
+				addVerdicts(j.normalizeVerdict(Bad), revs[:1])
+				addVerdicts(j.normalizeVerdict(Good), revs[1:])
+			}
+		case termOld, "old":
+			addVerdicts(j.normalizeVerdict(Good), args)
+		case termNew, "new":
+			addVerdicts(j.normalizeVerdict(Bad), args)
+		case "skip":
+			addVerdicts(Skip, args)
+		case "reset", "terms", "log":
+			// Don't affect the verdicts
+		default:
+			return nil, fmt.Errorf("line %d contains unsupported git bisect command %q", lineNumber, command)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return verdicts, nil
+}
+
+// WriteBisectLog writes the verdicts received by the replica with the passed index, as stored in the job's checkpoint file, in the format of git bisect log.
+// The written log can be replayed using git bisect replay, or used to seed a new job via [Job.ParseBisectLog] and KnownVerdicts.
+// Verdicts of commits which were created by biscepter to split up octopus commits are left out, as they don't exist in the repository.
+func (j *Job) WriteBisectLog(w io.Writer, replicaIndex int) error {
+	if err := j.setTerms(); err != nil {
+		return err
+	}
+
+	cp, err := j.readCheckpoint()
+	if err != nil {
+		return err
+	}
+	var rc *replicaCheckpoint
+	for i := range cp.Replicas {
+		if cp.Replicas[i].Index == replicaIndex {
+			rc = &cp.Replicas[i]
+		}
+	}
+	if rc == nil {
+		return fmt.Errorf("checkpoint %s contains no replica with index %d", j.Checkpoint, replicaIndex)
+	}
+
+	termOld, termNew := j.getGitTerms()
+
+	start := []string{"git", "bisect", "start"}
+	if termOld != "good" && termOld != "old" {
+		start = append(start, fmt.Sprintf("'--term-old=%s'", termOld), fmt.Sprintf("'--term-new=%s'", termNew))
+	}
+	oldCommit, newCommit := j.getOldAndNewCommits()
+	start = append(start, fmt.Sprintf("'%s'", newCommit), fmt.Sprintf("'%s'", oldCommit))
+	if len(j.Paths) != 0 {
+		start = append(start, "'--'")
+		for _, path := range j.Paths {
+			start = append(start, fmt.Sprintf("'%s'", path))
+		}
+	}
+
+	lines := []string{fmt.Sprintf("# biscepter log of replica %d, %s: %s, %s: %s", replicaIndex, termOld, j.TermOld, termNew, j.TermNew), strings.Join(start, " ")}
+	for _, verdict := range rc.Verdicts {
+		if octopusCommit, ok := rc.OctopusMerges[verdict.Commit]; ok {
+			lines = append(lines, fmt.Sprintf("# %s: [%s] was created to split up octopus commit %s, leaving it out", j.TermOfVerdict(verdict.Verdict), verdict.Commit, octopusCommit))
+			continue
+		}
+
+		term := "skip"
+		switch j.normalizeVerdict(verdict.Verdict) {
+		case Good:
+			term = termOld
+		case Bad:
+			term = termNew
+		}
+
+		comment := fmt.Sprintf("# %s: [%s]", j.TermOfVerdict(verdict.Verdict), verdict.Commit)
+		if !verdict.Timestamp.IsZero() {
+			comment += " " + verdict.Timestamp.Format(time.RFC3339)
+		}
+		if verdict.Notes != "" {
+			comment += " " + strings.ReplaceAll(verdict.Notes, "\n", " ")
+		}
+		lines = append(lines, comment, fmt.Sprintf("git bisect %s %s", term, verdict.Commit))
+	}
+
+	_, err = io.WriteString(w, strings.Join(lines, "\n")+"\n")
+	return err
+}
+
+// getGitTerms returns the terms to use for the old and new commits when writing a git bisect log.
+// These are the job's terms if git bisect accepts them, or "old" and "new" otherwise, e.g. for reversed jobs without custom terms.
+func (j *Job) getGitTerms() (string, string) {
+	if j.TermOld == "good" && j.TermNew == "bad" {
+		return j.TermOld, j.TermNew
+	}
+	for _, term := range []string{j.TermOld, j.TermNew} {
+		for _, reserved := range append(reservedGitTerms, "good", "bad", "old", "new") {
+			if strings.EqualFold(term, reserved) {
+				return "old", "new"
+			}
+		}
+	}
+	return j.TermOld, j.TermNew
+}
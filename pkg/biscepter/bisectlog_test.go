@@ -0,0 +1,199 @@
+package biscepter
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseBisectLog(t *testing.T) {
+	testCases := []struct {
+		name     string
+		reverse  bool
+		log      string
+		verdicts []VerdictRecord
+		wantErr  bool
+	}{
+		{
+			name: "Log of git bisect",
+			log: `git bisect start
+# status: waiting for both good and bad commits
+# bad: [e] e
+git bisect bad e
+# good: [a] a
+git bisect good a
+# skip: [c] c
+git bisect skip c
+git bisect good b
+`,
+			verdicts: []VerdictRecord{{Commit: "e", Verdict: Bad}, {Commit: "a", Verdict: Good}, {Commit: "c", Verdict: Skip}, {Commit: "b", Verdict: Good}},
+		},
+		{
+			name: "Start with commits and paths",
+			log: `git bisect start 'e' 'a' 'b' '--' 'src'
+git bisect bad d`,
+			verdicts: []VerdictRecord{{Commit: "e", Verdict: Bad}, {Commit: "a", Verdict: Good}, {Commit: "b", Verdict: Good}, {Commit: "d", Verdict: Bad}},
+		},
+		{
+			name: "Custom terms",
+			log: `git bisect start '--term-old=fast' '--term-new' 'slow'
+git bisect slow e
+git bisect fast a
+git bisect old b
+git bisect new d`,
+			verdicts: []VerdictRecord{{Commit: "e", Verdict: Bad}, {Commit: "a", Verdict: Good}, {Commit: "b", Verdict: Good}, {Commit: "d", Verdict: Bad}},
+		},
+		{
+			name:    "Reversed job",
+			reverse: true,
+			log: `git bisect start --term-old broken --term-new fixed
+git bisect fixed e
+git bisect broken a`,
+			verdicts: []VerdictRecord{{Commit: "e", Verdict: Good}, {Commit: "a", Verdict: Bad}},
+		},
+		{
+			name:    "Unsupported command",
+			log:     "git bisect run ./test.sh",
+			wantErr: true,
+		},
+		{
+			name:    "No git bisect command",
+			log:     "git log",
+			wantErr: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			job := &Job{Reverse: testCase.reverse}
+			verdicts, err := job.ParseBisectLog(strings.NewReader(testCase.log))
+			if testCase.wantErr {
+				assert.Error(t, err, "ParseBisectLog didn't return an error")
+				return
+			}
+			assert.NoError(t, err, "ParseBisectLog returned an error")
+			assert.Equal(t, testCase.verdicts, verdicts, "Wrong verdicts parsed")
+		})
+	}
+}
+
+func TestWriteBisectLog(t *testing.T) {
+	newJob := func(reverse bool, termOld, termNew string) *Job {
+		return &Job{
+			Repository: "repo",
+			GoodCommit: "a",
+			BadCommit:  "e",
+			Reverse:    reverse,
+			TermOld:    termOld,
+			TermNew:    termNew,
+			Paths:      []string{"src"},
+
+			Checkpoint:         filepath.Join(t.TempDir(), "checkpoint"),
+			checkpointReplicas: make(map[int]replicaCheckpoint),
+		}
+	}
+	writeVerdicts := func(job *Job, verdicts []VerdictRecord) {
+		// Like when running the job, the terms are set before any checkpoint is written
+		assert.NoError(t, job.setTerms(), "Failed to set terms")
+		rep := &replica{
+			parentJob:        job,
+			index:            1,
+			commits:          []string{"a", "b", "c", "d", "e"},
+			goodCommitOffset: 0,
+			badCommitOffset:  4,
+			skippedCommits:   make(map[int]bool),
+			verdicts:         verdicts,
+			octopusMerges:    map[string]string{"s": "o"},
+			log:              logrus.NewEntry(logrus.StandardLogger()),
+		}
+		rep.saveCheckpoint()
+	}
+	timestamp := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	verdicts := []VerdictRecord{
+		{Commit: "c", Verdict: Good, Timestamp: timestamp, Notes: "works\nfine"},
+		{Commit: "s", Verdict: Bad},
+		{Commit: "d", Verdict: Bad},
+		{Commit: "b", Verdict: Skip},
+	}
+
+	t.Run("Default terms", func(t *testing.T) {
+		job := newJob(false, "", "")
+		writeVerdicts(job, verdicts)
+
+		var buf bytes.Buffer
+		assert.NoError(t, job.WriteBisectLog(&buf, 1), "WriteBisectLog returned an error")
+		lines := strings.Split(buf.String(), "\n")
+		assert.Equal(t, "git bisect start 'e' 'a' '--' 'src'", lines[1], "Wrong start line")
+		assert.Equal(t, "# good: [c] 2024-01-02T03:04:05Z works fine", lines[2], "Wrong comment")
+		assert.Contains(t, buf.String(), "# bad: [s] was created to split up octopus commit o", "Synthetic commit wasn't left out")
+		assert.NotContains(t, buf.String(), "git bisect bad s", "Synthetic commit wasn't left out")
+
+		parsed, err := job.ParseBisectLog(&buf)
+		assert.NoError(t, err, "Written log couldn't be parsed")
+		assert.Equal(t, []VerdictRecord{
+			{Commit: "e", Verdict: Bad},
+			{Commit: "a", Verdict: Good},
+			{Commit: "c", Verdict: Good},
+			{Commit: "d", Verdict: Bad},
+			{Commit: "b", Verdict: Skip},
+		}, parsed, "Wrong verdicts after round trip")
+	})
+
+	t.Run("Reversed job", func(t *testing.T) {
+		job := newJob(true, "", "")
+		writeVerdicts(job, []VerdictRecord{{Commit: "c", Verdict: Good}})
+
+		var buf bytes.Buffer
+		assert.NoError(t, job.WriteBisectLog(&buf, 1), "WriteBisectLog returned an error")
+		assert.Contains(t, buf.String(), "git bisect start 'a' 'e'", "Wrong start line")
+		assert.Contains(t, buf.String(), "git bisect new c", "Wrong term for good commit of reversed job")
+	})
+
+	t.Run("Custom terms", func(t *testing.T) {
+		job := newJob(false, "fast", "slow")
+		writeVerdicts(job, []VerdictRecord{{Commit: "c", Verdict: Bad}})
+
+		var buf bytes.Buffer
+		assert.NoError(t, job.WriteBisectLog(&buf, 1), "WriteBisectLog returned an error")
+		assert.Contains(t, buf.String(), "git bisect start '--term-old=fast' '--term-new=slow' 'e' 'a'", "Wrong start line")
+		assert.Contains(t, buf.String(), "git bisect slow c", "Wrong custom term")
+	})
+
+	t.Run("Unknown replica", func(t *testing.T) {
+		job := newJob(false, "", "")
+		writeVerdicts(job, verdicts)
+		assert.Error(t, job.WriteBisectLog(&bytes.Buffer{}, 0), "No error for replica without checkpoint")
+	})
+}
+
+func TestGetJobFromConfigKnownVerdicts(t *testing.T) {
+	bisectLogPath := filepath.Join(t.TempDir(), "bisect.log")
+	assert.NoError(t, os.WriteFile(bisectLogPath, []byte("git bisect start --term-old broken --term-new fixed\ngit bisect fixed e\ngit bisect skip c\n"), 0644), "Couldn't write bisect log")
+
+	yml := fmt.Sprintf(`
+repository: "repo"
+port: 80
+reverse: true
+knownVerdicts:
+  - bisectLog: %s
+    good: [b]
+  - bad: [a, c]
+    skip: [d]
+`, bisectLogPath)
+	job, err := GetJobFromConfig(strings.NewReader(yml))
+	assert.NoError(t, err, "GetJobFromConfig returned an error")
+	assert.Equal(t, [][]VerdictRecord{
+		{{Commit: "e", Verdict: Good}, {Commit: "c", Verdict: Skip}, {Commit: "b", Verdict: Good}},
+		{{Commit: "a", Verdict: Bad}, {Commit: "c", Verdict: Bad}, {Commit: "d", Verdict: Skip}},
+	}, job.KnownVerdicts, "Wrong known verdicts")
+
+	_, err = GetJobFromConfig(strings.NewReader(strings.ReplaceAll(yml, bisectLogPath, bisectLogPath+".missing")))
+	assert.Error(t, err, "GetJobFromConfig didn't return an error for a missing bisect log")
+}
@@ -0,0 +1,293 @@
+package biscepter
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+)
+
+// A checkpoint holds the bisection state of all replicas of a job, such that the job can be resumed after biscepter was stopped
+type checkpoint struct {
+	Repository string `json:"repository"`
+	GoodCommit string `json:"goodCommit"`
+	BadCommit  string `json:"badCommit"`
+
+	checkpointSettings
+
+	Replicas []replicaCheckpoint `json:"replicas"`
+}
+
+// checkpointSettings holds the settings of a job which change the meaning of the commits and verdicts of its checkpoint
+type checkpointSettings struct {
+	Mode BisectionMode `json:"mode"`
+
+	Reverse bool   `json:"reverse"`
+	TermOld string `json:"termOld"`
+	TermNew string `json:"termNew"`
+
+	Paths       []string    `json:"paths,omitempty"`
+	FullHistory bool        `json:"fullHistory"`
+	MergePolicy MergePolicy `json:"mergePolicy"`
+}
+
+// equal returns whether the passed settings are the same as these settings
+func (s checkpointSettings) equal(other checkpointSettings) bool {
+	return s.Mode == other.Mode &&
+		s.Reverse == other.Reverse && s.TermOld == other.TermOld && s.TermNew == other.TermNew &&
+		slices.Equal(s.Paths, other.Paths) && s.FullHistory == other.FullHistory && s.MergePolicy == other.MergePolicy
+}
+
+// A replicaCheckpoint holds the bisection state of a single replica
+type replicaCheckpoint struct {
+	Index int `json:"index"`
+
+	Commits []string `json:"commits"`
+
+	GoodCommitOffset int `json:"goodCommitOffset"`
+	BadCommitOffset  int `json:"badCommitOffset"`
+
+	SkippedCommits       []int    `json:"skippedCommits"`
+	PossibleOtherCommits []string `json:"possibleOtherCommits"`
+
+	MergePath     []MergeStep       `json:"mergePath"`
+	OctopusMerges map[string]string `json:"octopusMerges"`
+
+	Verdicts        []VerdictRecord `json:"verdicts"`
+	Inconsistencies []string        `json:"inconsistencies,omitempty"`
+	Restarts        int             `json:"restarts,omitempty"`
+
+	Posterior  []float64 `json:"posterior,omitempty"`
+	Candidates []uint64  `json:"candidates,omitempty"`
+
+	Cancelled bool `json:"cancelled,omitempty"`
+}
+
+// Resume runs the job like [Job.Run], but restores the state of every replica from the job's checkpoint file first.
+// Replicas without a checkpoint start their bisection from scratch, replicas which were cancelled aren't started again, and ReplicasCount is raised to the amount of replicas in the checkpoint if it is lower.
+// The checkpoint has to belong to a job with the same repository, good commit and bad commit,
+// which was run with the same mode, direction, terms, paths, history and merge policy.
+func (job *Job) Resume() (chan RunningSystem, chan OffendingCommit, error) {
+	cp, err := job.readCheckpoint()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	job.checkpointReplicas = make(map[int]replicaCheckpoint)
+	for _, rc := range cp.Replicas {
+		job.checkpointReplicas[rc.Index] = rc
+		job.ReplicasCount = max(job.ReplicasCount, rc.Index+1)
+	}
+
+	return job.Run()
+}
+
+// readCheckpoint reads the job's checkpoint file and makes sure it belongs to this job
+func (job *Job) readCheckpoint() (*checkpoint, error) {
+	if job.Checkpoint == "" {
+		job.Checkpoint = ".biscepter-checkpoint~"
+	}
+
+	checkpointBytes, err := os.ReadFile(job.Checkpoint)
+	if err != nil {
+		return nil, errors.Join(fmt.Errorf("couldn't read checkpoint %s", job.Checkpoint), err)
+	}
+	var cp checkpoint
+	if err := json.Unmarshal(checkpointBytes, &cp); err != nil {
+		return nil, errors.Join(fmt.Errorf("couldn't parse checkpoint %s", job.Checkpoint), err)
+	}
+
+	if cp.Repository != job.Repository || cp.GoodCommit != job.GoodCommit || cp.BadCommit != job.BadCommit {
+		return nil, fmt.Errorf("checkpoint %s belongs to a job bisecting %s from %s to %s", job.Checkpoint, cp.Repository, cp.GoodCommit, cp.BadCommit)
+	}
+	if err := job.setTerms(); err != nil {
+		return nil, errors.Join(fmt.Errorf("invalid terms"), err)
+	}
+	if settings := job.getCheckpointSettings(); !cp.checkpointSettings.equal(settings) {
+		return nil, fmt.Errorf("checkpoint %s belongs to a job with the settings %+v instead of %+v", job.Checkpoint, cp.checkpointSettings, settings)
+	}
+	return &cp, nil
+}
+
+// getCheckpointSettings returns the settings of this job which are stored in its checkpoint.
+// The job's terms have to be set when calling this method.
+func (job *Job) getCheckpointSettings() checkpointSettings {
+	return checkpointSettings{
+		Mode: job.Mode,
+
+		Reverse: job.Reverse,
+		TermOld: job.TermOld,
+		TermNew: job.TermNew,
+
+		Paths:       job.Paths,
+		FullHistory: job.FullHistory,
+		MergePolicy: job.MergePolicy,
+	}
+}
+
+// writeCheckpoint updates the checkpoint of the passed replica and writes the checkpoints of all replicas to the job's checkpoint file.
+// The file is replaced atomically, so that it always contains a complete checkpoint.
+func (job *Job) writeCheckpoint(rc replicaCheckpoint) error {
+	if job.Checkpoint == os.DevNull {
+		return nil
+	}
+
+	job.checkpointLock.Lock()
+	defer job.checkpointLock.Unlock()
+
+	job.checkpointReplicas[rc.Index] = rc
+
+	cp := checkpoint{
+		Repository: job.Repository,
+		GoodCommit: job.GoodCommit,
+		BadCommit:  job.BadCommit,
+
+		checkpointSettings: job.getCheckpointSettings(),
+	}
+	for _, rc := range job.checkpointReplicas {
+		cp.Replicas = append(cp.Replicas, rc)
+	}
+	sort.Slice(cp.Replicas, func(i, j int) bool {
+		return cp.Replicas[i].Index < cp.Replicas[j].Index
+	})
+
+	checkpointBytes, err := json.Marshal(cp)
+	if err != nil {
+		return errors.Join(fmt.Errorf("couldn't encode checkpoint"), err)
+	}
+	tmpPath := job.Checkpoint + ".tmp"
+	if err := os.WriteFile(tmpPath, checkpointBytes, 0644); err != nil {
+		return errors.Join(fmt.Errorf("couldn't write checkpoint to %s", tmpPath), err)
+	}
+	if err := os.Rename(tmpPath, job.Checkpoint); err != nil {
+		return errors.Join(fmt.Errorf("couldn't move checkpoint to %s", job.Checkpoint), err)
+	}
+	return nil
+}
+
+// saveCheckpoint writes this replica's current bisection state to the job's checkpoint file.
+// The lock of waitingCond has to be held when calling this method.
+func (r *replica) saveCheckpoint() {
+	if err := r.parentJob.writeCheckpoint(r.getCheckpoint()); err != nil {
+		r.log.Errorf("Failed to write checkpoint - %v", err)
+	}
+}
+
+// getCheckpoint returns this replica's current bisection state
+func (r replica) getCheckpoint() replicaCheckpoint {
+	skippedCommits := []int{}
+	for commitOffset, skipped := range r.skippedCommits {
+		if skipped {
+			skippedCommits = append(skippedCommits, commitOffset)
+		}
+	}
+	sort.Ints(skippedCommits)
+
+	// The octopus merges are modified in place, so they have to be copied
+	var octopusMerges map[string]string
+	if r.octopusMerges != nil {
+		octopusMerges = make(map[string]string)
+		for commit, octopusCommit := range r.octopusMerges {
+			octopusMerges[commit] = octopusCommit
+		}
+	}
+
+	return replicaCheckpoint{
+		Index: r.index,
+
+		Commits: r.commits,
+
+		GoodCommitOffset: r.goodCommitOffset,
+		BadCommitOffset:  r.badCommitOffset,
+
+		SkippedCommits:       skippedCommits,
+		PossibleOtherCommits: r.possibleOtherCommits,
+
+		MergePath:     r.mergePath,
+		OctopusMerges: octopusMerges,
+
+		Verdicts:        r.verdicts,
+		Inconsistencies: r.inconsistencies,
+		Restarts:        r.restarts,
+
+		Posterior:  r.posterior,
+		Candidates: r.candidates,
+
+		Cancelled: r.isCancelled,
+	}
+}
+
+// restoreCheckpoint sets this replica's bisection state to the passed checkpoint.
+// If the checkpoint doesn't fit the replica's commits, an error is returned and the replica is left unchanged.
+func (r *replica) restoreCheckpoint(rc replicaCheckpoint) error {
+	if rootMerge := rc.getRootMerge(); rootMerge != "" && !slices.Contains(r.commits, rootMerge) {
+		return fmt.Errorf("merge commit %s descended into by checkpoint of replica %d isn't one of the job's commits", rootMerge, rc.Index)
+	} else if rootMerge == "" && !slices.Equal(rc.Commits, r.commits) {
+		return fmt.Errorf("commits of checkpoint of replica %d differ from the job's commits", rc.Index)
+	}
+	if rc.GoodCommitOffset < 0 || rc.GoodCommitOffset >= rc.BadCommitOffset || rc.BadCommitOffset >= len(rc.Commits) {
+		return fmt.Errorf("commit window %d to %d of checkpoint of replica %d is invalid", rc.GoodCommitOffset, rc.BadCommitOffset, rc.Index)
+	}
+	if (rc.Posterior != nil) != (r.posterior != nil) || (rc.Posterior != nil && len(rc.Posterior) != len(rc.Commits)) {
+		return fmt.Errorf("posterior of checkpoint of replica %d doesn't fit the job", rc.Index)
+	}
+	if (rc.Candidates != nil) != (r.candidates != nil) || (rc.Candidates != nil && len(rc.Candidates) != len(r.candidates)) {
+		return fmt.Errorf("candidates of checkpoint of replica %d don't fit the job", rc.Index)
+	}
+
+	// Commits created when splitting up octopus commits only exist in the repositories of the previous run
+	created := make(map[string]bool)
+	for _, octopusCommit := range rc.OctopusMerges {
+		if created[octopusCommit] {
+			continue
+		}
+		firstParent, err := getFirstParent(octopusCommit, r.repoPath)
+		if err != nil {
+			return err
+		}
+		if _, err := createOctopusChain(octopusCommit, firstParent, r.repoCopies); err != nil {
+			return errors.Join(fmt.Errorf("couldn't recreate octopus chain of %s for replica %d", octopusCommit, rc.Index), err)
+		}
+		created[octopusCommit] = true
+	}
+
+	r.setState(rc)
+
+	r.log.Infof("Restored checkpoint with %d verdicts, remaining window from commit %d to %d", len(r.verdicts), r.goodCommitOffset, r.badCommitOffset)
+	return nil
+}
+
+// getRootMerge returns the merge commit of the job's commits which the replica descended into, or an empty string if it didn't descend into any merge commit
+func (rc replicaCheckpoint) getRootMerge() string {
+	if len(rc.MergePath) != 0 {
+		return rc.MergePath[0].MergeCommit
+	}
+	// The replica is still bisecting the parents of the first octopus commit, which is the same for all of the chain's commits
+	for _, octopusCommit := range rc.OctopusMerges {
+		return octopusCommit
+	}
+	return ""
+}
+
+// setState sets this replica's bisection state to the passed state, as returned by getCheckpoint
+func (r *replica) setState(rc replicaCheckpoint) {
+	r.commits = rc.Commits
+	r.goodCommitOffset = rc.GoodCommitOffset
+	r.badCommitOffset = rc.BadCommitOffset
+	r.skippedCommits = make(map[int]bool)
+	for _, commitOffset := range rc.SkippedCommits {
+		r.skippedCommits[commitOffset] = true
+	}
+	r.possibleOtherCommits = rc.PossibleOtherCommits
+	r.mergePath = rc.MergePath
+	r.octopusMerges = rc.OctopusMerges
+	r.verdicts = rc.Verdicts
+	r.inconsistencies = rc.Inconsistencies
+	r.restarts = rc.Restarts
+	r.offendingCommit, r.verificationVerdicts = nil, nil
+	r.posterior = rc.Posterior
+	r.candidates = rc.Candidates
+	r.isCancelled = rc.Cancelled
+}
@@ -0,0 +1,106 @@
+package biscepter
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckpoint(t *testing.T) {
+	job := &Job{
+		Repository: "repo",
+		GoodCommit: "a",
+		BadCommit:  "e",
+		TermOld:    "good",
+		TermNew:    "bad",
+
+		Checkpoint:         filepath.Join(t.TempDir(), "checkpoint"),
+		checkpointReplicas: make(map[int]replicaCheckpoint),
+
+		commits: []string{"a", "b", "c", "d", "e"},
+	}
+	newReplica := func(index int) *replica {
+		return &replica{
+			parentJob:        job,
+			index:            index,
+			commits:          job.commits,
+			goodCommitOffset: 0,
+			badCommitOffset:  len(job.commits) - 1,
+			skippedCommits:   make(map[int]bool),
+			log:              logrus.NewEntry(logrus.StandardLogger()),
+		}
+	}
+
+	t.Run("Checkpoint is restored", func(t *testing.T) {
+		rep := newReplica(1)
+		rep.applyVerdict(1, Good)
+		rep.applyVerdict(2, Skip)
+		rep.verdicts = []VerdictRecord{{Commit: "b", Verdict: Good}, {Commit: "c", Verdict: Skip}}
+		rep.saveCheckpoint()
+		newReplica(0).saveCheckpoint()
+
+		checkpointBytes, err := os.ReadFile(job.Checkpoint)
+		assert.NoError(t, err, "Checkpoint wasn't written")
+		var cp checkpoint
+		assert.NoError(t, json.Unmarshal(checkpointBytes, &cp), "Checkpoint couldn't be parsed")
+		assert.Equal(t, "repo", cp.Repository, "Wrong repository in checkpoint")
+		assert.Len(t, cp.Replicas, 2, "Wrong amount of replicas in checkpoint")
+		assert.Equal(t, 0, cp.Replicas[0].Index, "Replicas in checkpoint are not sorted")
+
+		restored := newReplica(1)
+		assert.NoError(t, restored.restoreCheckpoint(cp.Replicas[1]), "Checkpoint couldn't be restored")
+		assert.Equal(t, 1, restored.goodCommitOffset, "Wrong good commit offset after restoring")
+		assert.Equal(t, 4, restored.badCommitOffset, "Wrong bad commit offset after restoring")
+		assert.Equal(t, map[int]bool{2: true}, restored.skippedCommits, "Wrong skipped commits after restoring")
+		assert.Equal(t, rep.verdicts, restored.verdicts, "Wrong verdicts after restoring")
+		assert.Equal(t, 3, restored.getNextCommit(), "Wrong next commit after restoring")
+	})
+
+	t.Run("Mismatching checkpoints are rejected", func(t *testing.T) {
+		rc := newReplica(0).getCheckpoint()
+		rc.Commits = []string{"a", "x", "e"}
+		rc.BadCommitOffset = 2
+		assert.Error(t, newReplica(0).restoreCheckpoint(rc), "Checkpoint with different commits was restored")
+
+		rc = newReplica(0).getCheckpoint()
+		rc.BadCommitOffset = 5
+		assert.Error(t, newReplica(0).restoreCheckpoint(rc), "Checkpoint with invalid window was restored")
+
+		rc = newReplica(0).getCheckpoint()
+		rc.Posterior = []float64{0, 0.25, 0.25, 0.25, 0.25}
+		assert.Error(t, newReplica(0).restoreCheckpoint(rc), "Probabilistic checkpoint was restored for a regular replica")
+
+		rc = newReplica(0).getCheckpoint()
+		rc.Commits = []string{"x", "y", "z"}
+		rc.BadCommitOffset = 2
+		rc.MergePath = []MergeStep{{MergeCommit: "m", MergedParent: "z"}}
+		assert.Error(t, newReplica(0).restoreCheckpoint(rc), "Checkpoint descended into a foreign merge commit was restored")
+
+		rc.MergePath = []MergeStep{{MergeCommit: "c", MergedParent: "z"}}
+		assert.NoError(t, newReplica(0).restoreCheckpoint(rc), "Checkpoint descended into a merge commit couldn't be restored")
+	})
+
+	t.Run("Checkpoints of jobs with different settings are rejected", func(t *testing.T) {
+		newReplica(0).saveCheckpoint()
+		_, err := job.readCheckpoint()
+		assert.NoError(t, err, "Checkpoint of the same job was rejected")
+
+		otherJobs := map[string]*Job{
+			"paths":        {Paths: []string{"src"}, TermOld: "good", TermNew: "bad"},
+			"direction":    {Reverse: true},
+			"terms":        {TermOld: "working", TermNew: "broken"},
+			"merge policy": {MergePolicy: MergePolicy{Type: MergeNever}, TermOld: "good", TermNew: "bad"},
+			"mode":         {Mode: BuildMode, TermOld: "good", TermNew: "bad"},
+		}
+		for setting, otherJob := range otherJobs {
+			otherJob.Repository, otherJob.GoodCommit, otherJob.BadCommit = job.Repository, job.GoodCommit, job.BadCommit
+			otherJob.Checkpoint = job.Checkpoint
+			_, err = otherJob.readCheckpoint()
+			assert.Error(t, err, "Checkpoint of job with different %s was accepted", setting)
+		}
+	})
+}
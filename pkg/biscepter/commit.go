@@ -3,7 +3,9 @@ package biscepter
 import (
 	"errors"
 	"fmt"
+	"os"
 	"os/exec"
+	"slices"
 	"strings"
 	"sync"
 )
@@ -11,9 +13,14 @@ import (
 // getCommitsBetween returns the hashes of all commits between the passed good and bad commit.
 // The hashes of the commits included in the avoidedCommits argument will not be included in the returned result.
 // The passed commits are included in the result.
+// If any paths are passed, only commits modifying at least one of them are returned, apart from the passed commits, which are always included.
 // The returned slice is ordered chronologically, starting from the good commit at index 0 and the bad commit at the last index
-func getCommitsBetween(goodCommitHash, badCommitHash, repoPath string) ([]string, error) {
-	cmd := exec.Command("git", "rev-list", "--reverse", "--first-parent", "^"+goodCommitHash, badCommitHash)
+func getCommitsBetween(goodCommitHash, badCommitHash, repoPath string, paths []string) ([]string, error) {
+	args := []string{"rev-list", "--reverse", "--first-parent", "^" + goodCommitHash, badCommitHash}
+	if len(paths) != 0 {
+		args = append(append(args, "--"), paths...)
+	}
+	cmd := exec.Command("git", args...)
 	cmd.Dir = repoPath
 	out, err := cmd.CombinedOutput()
 	if err != nil {
@@ -25,13 +32,19 @@ func getCommitsBetween(goodCommitHash, badCommitHash, repoPath string) ([]string
 	}
 
 	// Get excluded boundary commit
-	cmd = exec.Command("git", "rev-parse", goodCommitHash)
+	cmd = exec.Command("git", "rev-parse", goodCommitHash, badCommitHash)
 	cmd.Dir = repoPath
 	out, err = cmd.CombinedOutput()
 	if err != nil {
 		return nil, errors.Join(fmt.Errorf("failed to get rev-list of bad commit %s to good commit %s, output: %s", badCommitHash, goodCommitHash, out), err)
 	}
-	goodBoundaryCommit := string(out)[:len(out)-1]
+	boundaryCommits := strings.Split(string(out[:len(out)-1]), "\n")
+	goodBoundaryCommit, badBoundaryCommit := boundaryCommits[0], boundaryCommits[1]
+
+	// The bad commit is not included by rev-list if it doesn't modify any of the paths
+	if badBoundaryCommit != goodBoundaryCommit && (len(commits) == 0 || commits[len(commits)-1] != badBoundaryCommit) {
+		commits = append(commits, badBoundaryCommit)
+	}
 
 	return append([]string{goodBoundaryCommit}, commits...), nil
 }
@@ -44,9 +57,45 @@ func getActualCommit(commitHash string, commitReplacements *sync.Map) string {
 	return commitHash
 }
 
+// getReplacedCommits returns the commits which the passed commit is tested in place of, given the passed replacements, sorted by their hashes
+func getReplacedCommits(commitHash string, commitReplacements *sync.Map) []string {
+	replacedCommits := []string{}
+	commitReplacements.Range(func(key, _ any) bool {
+		if commit := key.(string); getActualCommit(commit, commitReplacements) == commitHash {
+			replacedCommits = append(replacedCommits, commit)
+		}
+		return true
+	})
+	slices.Sort(replacedCommits)
+	return replacedCommits
+}
+
+// resolveCommit returns the full hash of the passed commit, which may be abbreviated or any other revision understood by git
+func resolveCommit(commit, repoPath string) (string, error) {
+	cmd := exec.Command("git", "rev-parse", "--verify", "--end-of-options", commit+"^{commit}")
+	cmd.Dir = repoPath
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", errors.Join(fmt.Errorf("failed to resolve commit %s, output: %s", commit, out), err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// getFirstParent returns the commit hash of the first parent of the passed commit
+func getFirstParent(commitHash, repoPath string) (string, error) {
+	cmd := exec.Command("git", "rev-parse", commitHash+"^1")
+	cmd.Dir = repoPath
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", errors.Join(fmt.Errorf("failed to get first parent of commit %s, output: %s", commitHash, out), err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
 // getMergedParent returns the commit hash of the current commit's parent which got merged, given the
 // passed parent is on the branch the parent got merged on.
-// If the current commit is not a merge commit or an octopus commit, getMergedParent returns an empty string
+// If the current commit is not a merge commit or an octopus commit, getMergedParent returns an empty string.
+// Octopus commits are instead split up into regular merge commits using createOctopusChain
 func getMergedParent(curCommitHash, parentCommitHash, repoPath string) (string, error) {
 	cmd := exec.Command("git", "rev-parse", fmt.Sprintf("%s^@", curCommitHash))
 	cmd.Dir = repoPath
@@ -61,7 +110,6 @@ func getMergedParent(curCommitHash, parentCommitHash, repoPath string) (string,
 		return "", nil
 	} else if len(parents) > 2 {
 		// Octopus commit
-		// TODO: Maybe implementable?
 		return "", nil
 	}
 	// Merge commit!
@@ -74,3 +122,90 @@ func getMergedParent(curCommitHash, parentCommitHash, repoPath string) (string,
 
 	return "", fmt.Errorf("passed parent commit %s is not actually a parent of %s (%s or %s)", parentCommitHash, curCommitHash, parents[0], parents[1])
 }
+
+// createOctopusChain splits up the passed octopus commit, with the passed commit as its first parent, into a chain of regular merge commits.
+// Every commit of the chain merges one more of the octopus commit's parents, in order, on top of the previous commit:
+//
+//	C_1 = merge(P_0, P_1), C_2 = merge(C_1, P_2), ..., C_n = merge(C_n-1, P_n)
+//
+// where the last commit has the same tree as the octopus commit itself.
+// The commits are created in every one of the passed repositories, with fixed authors and dates such that their hashes are equal across repositories and runs.
+// The returned slice starts with the first parent, followed by all created commits.
+// If the octopus commit is no octopus commit, nil is returned. If the parents can't be merged without conflicts, an error is returned.
+func createOctopusChain(octopusCommitHash, parentCommitHash string, repoPaths []string) ([]string, error) {
+	git := func(repoPath string, env []string, args ...string) (string, error) {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = repoPath
+		cmd.Env = append(os.Environ(), env...)
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			return "", errors.Join(fmt.Errorf("git %s failed in %s, output: %s", strings.Join(args, " "), repoPath, out), err)
+		}
+		return strings.TrimSpace(string(out)), nil
+	}
+
+	var chain []string
+	for _, repoPath := range repoPaths {
+		out, err := git(repoPath, nil, "show", "-s", "--format=%P%n%T%n%cI", octopusCommitHash)
+		if err != nil {
+			return nil, err
+		}
+		lines := strings.Split(out, "\n")
+		parents, tree, date := strings.Fields(lines[0]), lines[1], lines[2]
+		if len(parents) <= 2 {
+			return nil, nil
+		}
+		if parents[0] != parentCommitHash {
+			return nil, fmt.Errorf("passed parent commit %s is not the first parent %s of octopus commit %s", parentCommitHash, parents[0], octopusCommitHash)
+		}
+
+		env := []string{
+			"GIT_AUTHOR_NAME=biscepter", "GIT_AUTHOR_EMAIL=biscepter@localhost", "GIT_AUTHOR_DATE=" + date,
+			"GIT_COMMITTER_NAME=biscepter", "GIT_COMMITTER_EMAIL=biscepter@localhost", "GIT_COMMITTER_DATE=" + date,
+		}
+
+		commits := []string{parents[0]}
+		for i, parent := range parents[1:] {
+			prev := commits[len(commits)-1]
+
+			mergeTree := tree
+			if i != len(parents)-2 {
+				// The last commit of the chain uses the octopus commit's tree to include any conflict resolutions
+				mergeTree, err = git(repoPath, nil, "merge-tree", "--write-tree", "--no-messages", prev, parent)
+				if err != nil {
+					return nil, errors.Join(fmt.Errorf("couldn't merge parent %s of octopus commit %s", parent, octopusCommitHash), err)
+				}
+				mergeTree = strings.Fields(mergeTree)[0]
+			}
+
+			message := fmt.Sprintf("biscepter: merge parent %d (%s) of octopus commit %s", i+1, parent, octopusCommitHash)
+			commit, err := git(repoPath, env, "commit-tree", mergeTree, "-p", prev, "-p", parent, "-m", message)
+			if err != nil {
+				return nil, err
+			}
+			commits = append(commits, commit)
+		}
+
+		if chain != nil && !slices.Equal(chain, commits) {
+			return nil, fmt.Errorf("octopus chain of %s differs between repositories: %v and %v", octopusCommitHash, chain, commits)
+		}
+		chain = commits
+	}
+
+	return chain, nil
+}
+
+// isAncestor returns whether the first passed commit is an ancestor of the second passed commit.
+// Like for git merge-base --is-ancestor, every commit is considered an ancestor of itself.
+func isAncestor(ancestorHash, commitHash, repoPath string) (bool, error) {
+	cmd := exec.Command("git", "merge-base", "--is-ancestor", ancestorHash, commitHash)
+	cmd.Dir = repoPath
+	out, err := cmd.CombinedOutput()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+		return false, nil
+	} else if err != nil {
+		return false, errors.Join(fmt.Errorf("failed to check whether %s is an ancestor of %s, output: %s", ancestorHash, commitHash, out), err)
+	}
+	return true, nil
+}
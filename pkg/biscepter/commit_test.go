@@ -0,0 +1,119 @@
+package biscepter
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dchest/uniuri"
+	"github.com/stretchr/testify/assert"
+)
+
+// runGit runs git with the passed arguments in the passed repository and returns its trimmed output, failing the test on errors
+func runGit(t *testing.T, repoPath string, args ...string) string {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = repoPath
+	cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@test", "GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@test")
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %v failed - %v, output: %s", args, err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// commitFile creates a commit in the passed repository modifying the passed file and returns the commit's hash
+func commitFile(t *testing.T, repoPath, file string) string {
+	path := filepath.Join(repoPath, file)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(uniuri.New()), 0644); err != nil {
+		t.Fatal(err)
+	}
+	runGit(t, repoPath, "add", "-A")
+	runGit(t, repoPath, "commit", "-q", "-m", file)
+	return runGit(t, repoPath, "rev-parse", "HEAD")
+}
+
+// createTestRepo creates a git repository with one commit per passed file, each modifying said file.
+// It returns the path to the repository and the hashes of the created commits in chronological order.
+func createTestRepo(t *testing.T, files []string) (string, []string) {
+	repoPath := t.TempDir()
+	runGit(t, repoPath, "init", "-q", "-b", "main")
+
+	commits := []string{}
+	for _, file := range files {
+		commits = append(commits, commitFile(t, repoPath, file))
+	}
+	return repoPath, commits
+}
+
+func TestGetCommitsBetween(t *testing.T) {
+	repoPath, commits := createTestRepo(t, []string{"a/0", "b/1", "a/2", "b/3", "c/4", "a/5", "b/6"})
+
+	values := []struct {
+		good  int
+		bad   int
+		paths []string
+
+		expected []int
+	}{
+		{0, 6, nil, []int{0, 1, 2, 3, 4, 5, 6}},
+		{2, 4, nil, []int{2, 3, 4}},
+		{3, 3, nil, []int{3}},
+		{0, 6, []string{"a"}, []int{0, 2, 5, 6}},
+		{0, 5, []string{"a"}, []int{0, 2, 5}},
+		{0, 6, []string{"b"}, []int{0, 1, 3, 6}},
+		{0, 6, []string{"a", "c"}, []int{0, 2, 4, 5, 6}},
+		{1, 4, []string{"a"}, []int{1, 2, 4}},
+		{3, 4, []string{"a"}, []int{3, 4}},
+	}
+
+	for _, v := range values {
+		result, err := getCommitsBetween(commits[v.good], commits[v.bad], repoPath, v.paths)
+		assert.NoErrorf(t, err, "getCommitsBetween returned an error for good %d, bad %d and paths %v", v.good, v.bad, v.paths)
+
+		expected := []string{}
+		for _, i := range v.expected {
+			expected = append(expected, commits[i])
+		}
+		assert.Equalf(t, expected, result, "Wrong commits for good %d, bad %d and paths %v", v.good, v.bad, v.paths)
+	}
+}
+
+func TestCreateOctopusChain(t *testing.T) {
+	repoPath, commits := createTestRepo(t, []string{"base"})
+	branches := []string{}
+	for _, branch := range []string{"a", "b", "c"} {
+		runGit(t, repoPath, "checkout", "-q", "-b", branch, commits[0])
+		branches = append(branches, commitFile(t, repoPath, branch))
+	}
+	runGit(t, repoPath, "checkout", "-q", "main")
+	first := commitFile(t, repoPath, "main")
+	runGit(t, repoPath, append([]string{"merge", "-q", "-m", "octopus"}, branches...)...)
+	octopus := runGit(t, repoPath, "rev-parse", "HEAD")
+
+	// A copy of the repository has to result in the same chain
+	copyPath := t.TempDir()
+	runGit(t, copyPath, "clone", "-q", repoPath, ".")
+
+	chain, err := createOctopusChain(octopus, first, []string{repoPath, copyPath})
+	assert.NoError(t, err, "createOctopusChain returned an error")
+	assert.Len(t, chain, 4, "Wrong length of octopus chain")
+	assert.Equal(t, first, chain[0], "Chain doesn't start with the first parent")
+	for i := 1; i < len(chain); i++ {
+		mergedParent, err := getMergedParent(chain[i], chain[i-1], repoPath)
+		assert.NoError(t, err, "getMergedParent returned an error")
+		assert.Equalf(t, branches[i-1], mergedParent, "Commit %d of the chain merged the wrong parent", i)
+	}
+	assert.Equal(t, runGit(t, repoPath, "rev-parse", octopus+"^{tree}"), runGit(t, repoPath, "rev-parse", chain[3]+"^{tree}"), "Last commit of the chain has a different tree than the octopus commit")
+
+	chain, err = createOctopusChain(branches[0], commits[0], []string{repoPath})
+	assert.NoError(t, err, "createOctopusChain returned an error for a regular commit")
+	assert.Nil(t, chain, "createOctopusChain returned a chain for a regular commit")
+
+	_, err = createOctopusChain(octopus, branches[0], []string{repoPath})
+	assert.Error(t, err, "createOctopusChain didn't return an error for a wrong first parent")
+}
@@ -0,0 +1,203 @@
+package biscepter
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/bits"
+	"os/exec"
+	"sort"
+	"strings"
+)
+
+// A commitSet is a set of commit offsets, stored as a bitset
+type commitSet []uint64
+
+// newCommitSet returns an empty set which can hold the offsets of size commits
+func newCommitSet(size int) commitSet {
+	return make(commitSet, (size+63)/64)
+}
+
+// has returns whether the passed commit offset is part of the set
+func (s commitSet) has(commitOffset int) bool {
+	return commitOffset >= 0 && commitOffset/64 < len(s) && s[commitOffset/64]&(1<<(commitOffset%64)) != 0
+}
+
+// add adds the passed commit offset to the set
+func (s commitSet) add(commitOffset int) {
+	s[commitOffset/64] |= 1 << (commitOffset % 64)
+}
+
+// remove removes the passed commit offset from the set
+func (s commitSet) remove(commitOffset int) {
+	s[commitOffset/64] &^= 1 << (commitOffset % 64)
+}
+
+// count returns the amount of commits in the set
+func (s commitSet) count() int {
+	count := 0
+	for _, word := range s {
+		count += bits.OnesCount64(word)
+	}
+	return count
+}
+
+// intersectionCount returns the amount of commits which are part of both sets
+func (s commitSet) intersectionCount(other commitSet) int {
+	count := 0
+	for i := range s {
+		count += bits.OnesCount64(s[i] & other[i])
+	}
+	return count
+}
+
+// getCommitGraphBetween returns the hashes of all commits which are ancestors of the passed bad commit but not of the passed good commit,
+// i.e. the whole commit DAG between the two commits instead of only the first-parent history.
+// The passed commits are included in the result.
+// If any paths are passed, only commits modifying at least one of them are returned, apart from the passed commits, which are always included.
+// The returned slice is ordered topologically, starting from the good commit at index 0 and the bad commit at the last index.
+//
+// Additionally, the ancestors of every returned commit are returned, where ancestors[i] contains the offsets of all ancestors of commits[i] within the returned commits,
+// apart from the good commit, which is not considered an ancestor of any commit.
+func getCommitGraphBetween(goodCommitHash, badCommitHash, repoPath string, paths []string) ([]string, []commitSet, error) {
+	args := []string{"rev-list", "--topo-order", "--reverse", "--parents", "^" + goodCommitHash, badCommitHash}
+	if len(paths) != 0 {
+		args = append(append(args, "--"), paths...)
+	}
+	cmd := exec.Command("git", args...)
+	cmd.Dir = repoPath
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return nil, nil, errors.Join(fmt.Errorf("failed to get rev-list of bad commit %s to good commit %s, output: %s", badCommitHash, goodCommitHash, out), err)
+	}
+	lines := []string{}
+	if len(out) != 0 {
+		lines = strings.Split(string(out[:len(out)-1]), "\n")
+	}
+
+	cmd = exec.Command("git", "rev-parse", goodCommitHash, badCommitHash)
+	cmd.Dir = repoPath
+	out, err = cmd.CombinedOutput()
+	if err != nil {
+		return nil, nil, errors.Join(fmt.Errorf("failed to get rev-list of bad commit %s to good commit %s, output: %s", badCommitHash, goodCommitHash, out), err)
+	}
+	boundaryCommits := strings.Split(string(out[:len(out)-1]), "\n")
+	goodBoundaryCommit, badBoundaryCommit := boundaryCommits[0], boundaryCommits[1]
+
+	commits := []string{goodBoundaryCommit}
+	parents := [][]string{nil}
+	for _, line := range lines {
+		fields := strings.Fields(line)
+		commits = append(commits, fields[0])
+		parents = append(parents, fields[1:])
+	}
+	// The bad commit is not included by rev-list if it doesn't modify any of the paths
+	if badBoundaryCommit != goodBoundaryCommit && commits[len(commits)-1] != badBoundaryCommit {
+		commits = append(commits, badBoundaryCommit)
+		parents = append(parents, commits[1:len(commits)-1])
+	}
+
+	offsets := make(map[string]int)
+	for i, commit := range commits {
+		offsets[commit] = i
+	}
+
+	// Since the commits are ordered topologically, all parents of a commit come before the commit itself
+	ancestors := make([]commitSet, len(commits))
+	for i := range commits {
+		ancestors[i] = newCommitSet(len(commits))
+		for _, parent := range parents[i] {
+			parentOffset, ok := offsets[parent]
+			if !ok || parentOffset == 0 {
+				// Parent is not a candidate
+				continue
+			}
+			ancestors[i].add(parentOffset)
+			for j := range ancestors[i] {
+				ancestors[i][j] |= ancestors[parentOffset][j]
+			}
+		}
+	}
+
+	return commits, ancestors, nil
+}
+
+// initCandidates sets this replica's candidates to all commits apart from the good commit
+func (r *replica) initCandidates() {
+	r.candidates = newCommitSet(len(r.commits))
+	for i := 1; i < len(r.commits); i++ {
+		r.candidates.add(i)
+	}
+}
+
+// applyGraphVerdict narrows down this replica's candidates using the passed normalized verdict for the commit with the passed offset.
+// A good commit removes itself and all of its ancestors from the candidates, while a bad commit removes all commits which are not its ancestors.
+// The candidates are replaced instead of modified, so that copies of the replica are unaffected.
+func (r *replica) applyGraphVerdict(commitOffset int, verdict Verdict) {
+	if !r.candidates.has(commitOffset) {
+		r.log.Warnf("Commit with offset %d is no candidate anymore, ignoring its verdict %s", commitOffset, verdict)
+		return
+	}
+
+	ancestors := r.parentJob.ancestors[commitOffset]
+	candidates := newCommitSet(len(r.commits))
+	for i := range candidates {
+		if verdict == Good {
+			candidates[i] = r.candidates[i] &^ ancestors[i]
+		} else {
+			candidates[i] = r.candidates[i] & ancestors[i]
+		}
+	}
+
+	if verdict == Good {
+		candidates.remove(commitOffset)
+	} else {
+		candidates.add(commitOffset)
+		r.badCommitOffset = commitOffset
+	}
+	r.candidates = candidates
+}
+
+// isParent returns whether the commit with the passed parent offset is a parent of the commit with the passed child offset,
+// i.e. an ancestor of it which isn't an ancestor of any of its other ancestors
+func (j *Job) isParent(parentOffset, childOffset int) bool {
+	if !j.ancestors[childOffset].has(parentOffset) {
+		return false
+	}
+	// Since the commits are ordered topologically, only commits in between can have the parent as an ancestor
+	for i := parentOffset + 1; i < childOffset; i++ {
+		if j.ancestors[childOffset].has(i) && j.ancestors[i].has(parentOffset) {
+			return false
+		}
+	}
+	return true
+}
+
+// getHalvingCommits returns the offsets of at most count untested candidates which best halve the remaining candidates,
+// like git bisect does for the whole commit DAG.
+// For every candidate, the amount of candidates which are its ancestors, including itself, is weighed against the remaining candidates.
+// Candidates are ordered by how evenly they split the candidates, preferring older commits on ties.
+func (r replica) getHalvingCommits(count int) []int {
+	total := r.candidates.count()
+
+	commitOffsets := []int{}
+	scores := make(map[int]int)
+	for i := 1; i < r.badCommitOffset; i++ {
+		if !r.isUntested(i) {
+			continue
+		}
+		weight := r.candidates.intersectionCount(r.parentJob.ancestors[i]) + 1
+		commitOffsets = append(commitOffsets, i)
+		scores[i] = min(weight, total-weight)
+	}
+
+	sort.SliceStable(commitOffsets, func(i, j int) bool {
+		return scores[commitOffsets[i]] > scores[commitOffsets[j]]
+	})
+	commitOffsets = commitOffsets[:min(count, len(commitOffsets))]
+
+	if len(commitOffsets) != 0 {
+		r.log.Infof("%d candidates left, next commit %d splits off %d of them. Expected amount of runs left: ~%.1f", total, commitOffsets[0], scores[commitOffsets[0]], math.Log2(float64(total)))
+	}
+	return commitOffsets
+}
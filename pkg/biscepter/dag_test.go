@@ -0,0 +1,110 @@
+package biscepter
+
+import (
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+// newGraphReplica returns a replica bisecting the full history of the following commits, where G is the good and D the bad commit:
+//
+//	G - A - B ----- M - D
+//	     \         /
+//	      F1 - F2
+func newGraphReplica() *replica {
+	ancestors := make([]commitSet, 7)
+	for i, offsets := range [][]int{{}, {}, {1}, {1}, {1, 2}, {1, 2, 3, 4}, {1, 2, 3, 4, 5}} {
+		ancestors[i] = newCommitSet(7)
+		for _, offset := range offsets {
+			ancestors[i].add(offset)
+		}
+	}
+
+	rep := &replica{
+		goodCommitOffset: 0,
+		badCommitOffset:  6,
+		commits:          []string{"G", "A", "F1", "B", "F2", "M", "D"},
+		skippedCommits:   make(map[int]bool),
+		log:              logrus.NewEntry(logrus.StandardLogger()),
+		parentJob: &Job{
+			FullHistory: true,
+			ancestors:   ancestors,
+		},
+	}
+	rep.initCandidates()
+	return rep
+}
+
+func TestGetCommitGraphBetween(t *testing.T) {
+	repoPath, commits := createTestRepo(t, []string{"good", "a"})
+	runGit(t, repoPath, "checkout", "-q", "-b", "feature")
+	f1 := commitFile(t, repoPath, "f1")
+	f2 := commitFile(t, repoPath, "f2")
+	runGit(t, repoPath, "checkout", "-q", "main")
+	b := commitFile(t, repoPath, "b")
+	runGit(t, repoPath, "merge", "-q", "--no-ff", "-m", "merge", "feature")
+	m := runGit(t, repoPath, "rev-parse", "HEAD")
+	d := commitFile(t, repoPath, "d")
+
+	result, ancestors, err := getCommitGraphBetween(commits[0], d, repoPath, nil)
+	assert.NoError(t, err, "getCommitGraphBetween returned an error")
+	assert.Len(t, result, 7, "Wrong amount of commits")
+	assert.Equal(t, commits[0], result[0], "Good commit is not the first commit")
+	assert.Equal(t, d, result[6], "Bad commit is not the last commit")
+	assert.ElementsMatch(t, []string{commits[0], commits[1], f1, f2, b, m, d}, result, "Wrong commits")
+
+	offsets := make(map[string]int)
+	for i, commit := range result {
+		offsets[commit] = i
+	}
+	isAncestor := func(ancestor, commit string) bool {
+		return ancestors[offsets[commit]].has(offsets[ancestor])
+	}
+	assert.True(t, isAncestor(f1, f2), "F1 is no ancestor of F2")
+	assert.True(t, isAncestor(f2, m), "F2 is no ancestor of the merge")
+	assert.True(t, isAncestor(b, m), "B is no ancestor of the merge")
+	assert.False(t, isAncestor(b, f2), "B is an ancestor of F2")
+	assert.False(t, isAncestor(commits[0], d), "Good commit is an ancestor of a candidate")
+	assert.Equal(t, 5, ancestors[6].count(), "Wrong amount of ancestors of the bad commit")
+
+	result, _, err = getCommitGraphBetween(commits[0], d, repoPath, []string{"f1", "f2"})
+	assert.NoError(t, err, "getCommitGraphBetween returned an error for paths")
+	assert.Equal(t, []string{commits[0], f1, f2, d}, result, "Wrong commits for paths")
+}
+
+func TestGetHalvingCommits(t *testing.T) {
+	t.Run("Initial commit halves the graph", func(t *testing.T) {
+		rep := newGraphReplica()
+		assert.Equal(t, 4, rep.getNextCommit(), "Wrong commit chosen for initial graph")
+		assert.Equal(t, []int{4, 2, 3}, rep.getNextCommits(3), "Wrong commits chosen for initial graph")
+	})
+
+	t.Run("Good verdict removes ancestors", func(t *testing.T) {
+		rep := newGraphReplica()
+		rep.applyVerdict(4, Good)
+		assert.Equal(t, 3, rep.candidates.count(), "Wrong amount of candidates left")
+		assert.Equal(t, 3, rep.getNextCommit(), "Wrong commit chosen after good verdict")
+
+		rep.applyVerdict(3, Bad)
+		assert.Equal(t, -1, rep.getNextCommit(), "Commit chosen after bisection finished")
+		assert.Equal(t, 3, rep.badCommitOffset, "Wrong bad commit")
+	})
+
+	t.Run("Bad verdict removes non-ancestors", func(t *testing.T) {
+		rep := newGraphReplica()
+		rep.applyVerdict(4, Bad)
+		assert.Equal(t, 3, rep.candidates.count(), "Wrong amount of candidates left")
+		assert.False(t, rep.isRelevant(3), "Commit which is no ancestor of the bad commit is still relevant")
+		assert.Equal(t, 1, rep.getNextCommit(), "Wrong commit chosen after bad verdict")
+
+		rep.applyVerdict(1, Good)
+		assert.Equal(t, 2, rep.getNextCommit(), "Wrong commit chosen after good verdict")
+	})
+
+	t.Run("Skipped commits are avoided", func(t *testing.T) {
+		rep := newGraphReplica()
+		rep.applyVerdict(4, Skip)
+		assert.Contains(t, []int{2, 3}, rep.getNextCommit(), "Skipped commit was chosen")
+	})
+}
@@ -14,6 +14,8 @@ After a job struct was acquired, the job can be started using [Job.Run].
 
 The [Job.Run] function returns two channels.
 The first of of these channels contains [RunningSystem]-s, which are to be used to determine whether a certain commit is good or bad using the [RunningSystem.IsGood] and [RunningSystem.IsBad] methods.
+If a commit cannot be tested, [RunningSystem.IsSkip] makes its replica continue with the nearest untested commit instead.
+For reversed jobs with custom terms, [RunningSystem.IsTerm] rates a system using the job's terms instead.
 The latter channel contains [OffendingCommit]-s, which represent a completed bisection and contain information about the offending commit of the bisected issue.
 
 When all issues have been diagnosed and an [OffendingCommit] was received for each one of them, the job can be stopped using [Job.Stop], which will shutdown all running docker containers.
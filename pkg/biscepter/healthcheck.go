@@ -96,7 +96,11 @@ func (h Healthcheck) performSingleHealthcheck(portsMapping map[int]int) (bool, e
 		if err != nil {
 			return false, err
 		}
-		return res.StatusCode == 200, nil
+		res.Body.Close()
+		if res.StatusCode != 200 {
+			return false, fmt.Errorf("GET %s returned status code %d", h.Data, res.StatusCode)
+		}
+		return true, nil
 	case Script:
 		cmd := exec.Command("sh", "-c", h.Data)
 		out := new(bytes.Buffer)
@@ -27,11 +27,12 @@ func TestPerformSingleHealthcheck(t *testing.T) {
 			port, err := strconv.Atoi(strings.Split(server.URL, ":")[2])
 			assert.Nil(t, err, "couldn't get port of testing server")
 
-			ok, _ := check.performSingleHealthcheck(map[int]int{
+			ok, err := check.performSingleHealthcheck(map[int]int{
 				1337: port,
 			})
 
 			assert.False(t, ok, "Unhealthy endpoint resulted in successful healthcheck")
+			assert.ErrorContains(t, err, "500", "Unhealthy endpoint didn't report its status code")
 		})
 		t.Run("Healthy endpoint succeeds", func(t *testing.T) {
 			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -30,16 +30,42 @@ type jobYaml struct {
 	GoodCommit string `yaml:"goodCommit"`
 	BadCommit  string `yaml:"badCommit"`
 
+	Reverse bool   `yaml:"reverse"`
+	TermOld string `yaml:"termOld"`
+	TermNew string `yaml:"termNew"`
+
+	Paths []string `yaml:"paths"`
+
+	FullHistory bool `yaml:"fullHistory"`
+
+	MergePolicy mergePolicyYaml `yaml:"mergePolicy"`
+
+	Mode string `yaml:"mode"`
+
+	KnownVerdicts []knownVerdictsYaml `yaml:"knownVerdicts"`
+
 	Host  string `yaml:"host"`
 	Port  int    `yaml:"port"`
 	Ports []int  `yaml:"ports"`
 
 	Healthcheck []healthcheckYaml `yaml:"healthcheck"`
 
+	Verdict string `yaml:"verdict"`
+
 	Dockerfile     string `yaml:"dockerfile"`
 	DockerfilePath string `yaml:"dockerfilePath"`
 
-	BuildCost float64 `yaml:"buildCost"`
+	BuildCost    float64 `yaml:"buildCost"`
+	BuildRetries *int    `yaml:"buildRetries"`
+
+	Parallelism int `yaml:"parallelism"`
+
+	SpeculativeBuilds    bool `yaml:"speculativeBuilds"`
+	MaxSpeculativeBuilds uint `yaml:"maxSpeculativeBuilds"`
+
+	Probabilistic *probabilisticYaml `yaml:"probabilistic"`
+
+	Verification *verificationYaml `yaml:"verification"`
 }
 
 // GetJobFromConfig reads in a job config in yaml format from a reader and initializes the corresponding job struct
@@ -54,28 +80,88 @@ func GetJobFromConfig(r io.Reader) (*Job, error) {
 
 	// Convert to Job struct
 	job := Job{
-		BuildCost: config.BuildCost,
+		BuildCost:    config.BuildCost,
+		BuildRetries: config.BuildRetries,
+
+		Parallelism: config.Parallelism,
+
+		SpeculativeBuilds:    config.SpeculativeBuilds,
+		MaxSpeculativeBuilds: config.MaxSpeculativeBuilds,
 
 		GoodCommit: config.GoodCommit,
 		BadCommit:  config.BadCommit,
 
+		Reverse: config.Reverse,
+		TermOld: config.TermOld,
+		TermNew: config.TermNew,
+
+		Paths: config.Paths,
+
+		FullHistory: config.FullHistory,
+
 		Host: config.Host,
 
 		Dockerfile:     config.Dockerfile,
 		DockerfilePath: config.DockerfilePath,
 
 		Repository: config.Repository,
+
+		VerdictCommand: config.Verdict,
+	}
+
+	mode, err := toBisectionMode(config.Mode)
+	if err != nil {
+		return nil, err
 	}
+	job.Mode = mode
 
 	job.Ports = config.Ports
 	if config.Port != 0 {
 		job.Ports = []int{config.Port}
 	}
 
-	if len(job.Ports) == 0 {
+	// Systems rated by a verdict command may not expose any ports, no systems are started in build mode,
+	// and systems in startup mode only need the ports of their healthchecks
+	if len(job.Ports) == 0 && job.VerdictCommand == "" && job.Mode == RunMode {
 		return nil, fmt.Errorf("no port specified for job")
 	}
 
+	if config.Probabilistic != nil {
+		if err := defaults.Set(config.Probabilistic); err != nil {
+			return nil, err
+		}
+		job.Probabilistic = &ProbabilisticConfig{
+			FalseGoodRate: config.Probabilistic.FalseGoodRate,
+			FalseBadRate:  config.Probabilistic.FalseBadRate,
+
+			Confidence: config.Probabilistic.Confidence,
+		}
+	}
+
+	if config.Verification != nil {
+		if err := defaults.Set(config.Verification); err != nil {
+			return nil, err
+		}
+		job.Verification = &VerificationConfig{
+			Runs:        config.Verification.Runs,
+			MaxRestarts: config.Verification.MaxRestarts,
+		}
+	}
+
+	mergePolicy, err := config.MergePolicy.toMergePolicy()
+	if err != nil {
+		return nil, err
+	}
+	job.MergePolicy = mergePolicy
+
+	for _, knownVerdicts := range config.KnownVerdicts {
+		verdicts, err := knownVerdicts.toVerdictRecords(&job)
+		if err != nil {
+			return nil, err
+		}
+		job.KnownVerdicts = append(job.KnownVerdicts, verdicts)
+	}
+
 	// Set all the healthchecks
 	checkTypes := map[string]HealthcheckType{
 		"http":   HttpGet200,
@@ -116,49 +202,131 @@ func GetJobFromConfig(r io.Reader) (*Job, error) {
 // A job represents a blueprint for replicas, which are then used to bisect one issue.
 // Jobs can create multiple replicas at once.
 type Job struct {
-	ReplicasCount int // How many replicas of itself this job should spawn simultaneously. Each replica is to be used for bisecting one issue.
+	ReplicasCount int // How many replicas of itself this job should spawn simultaneously. Each replica is to be used for bisecting one issue. Raised by [Job.AddReplica]
 
 	// The cost multiplier of building a commit compared to running an already built commit.
 	// A build cost of 100 means building a commit is 100 times more expensive than running a built commit.
 	// A build cost of less than 1 results in biscepter always building the middle commit (if it was not built yet) and not using nearby, cached, builds.
 	BuildCost float64
 
+	// How many times a build which failed transiently, e.g. due to a network error while pulling the base image or a hiccup of the docker daemon, is retried.
+	// The backoff between retries doubles every time. Commits are only replaced if a build step fails, never due to transient failures, but skipped once the retries are used up.
+	// Defaults to 3 if nil, while 0 disables retrying
+	BuildRetries *int
+
 	// The host to which the docker container ports should be exposed to. Defaults to 127.0.0.1.
 	// If you want the containers to be accessible from everywhere, set this to 0.0.0.0.
 	Host         string
 	Ports        []int         // The ports which this job needs
 	Healthchecks []Healthcheck // The healthchecks for this job
 
+	// A command which is run using `sh -c` inside the container of every system once it passed the healthchecks.
+	// If set, systems are not sent out to be tested, but rated according to the command's exit code instead, which is interpreted like for [VerdictFromExitCode].
+	// Unlike for verdict scripts, invalid exit codes don't abort the bisection: the commit is skipped instead, and the error is recorded in the output of its verdict.
+	// The same applies if the command couldn't be run at all.
+	// Note that the container has to keep running for the command to be executed, e.g. by using `sleep infinity` as its CMD.
+	VerdictCommand string
+
+	// What the verdict of a commit is based on. Defaults to RunMode, in which systems running the commits are tested.
+	// In BuildMode, only the commits' images are built and a failed build is a bad verdict, and in StartupMode, a failed healthcheck is a bad verdict.
+	// See [BisectionMode] for more information.
+	Mode BisectionMode
+
+	// If set, replicas bisect probabilistically, tolerating verdicts which are wrong some of the time, e.g. for flaky issues.
+	// See [ProbabilisticConfig] for more information.
+	Probabilistic *ProbabilisticConfig
+
+	// If set, replicas verify their offending commit by re-testing it and its predecessor before reporting it.
+	// See [VerificationConfig] for more information.
+	Verification *VerificationConfig
+
 	GoodCommit string // The hash of the good commit, i.e. the commit which does not exhibit any issues
 	BadCommit  string // The hash of the bad commit, i.e. the commit which exhibits the issue(s) to be bisected
 
+	// Whether to search for the commit which fixed an issue instead of the one which introduced it.
+	// If set, the good commit is newer than the bad commit, and the offending commit is the oldest good commit.
+	Reverse bool
+
+	// The terms for the behaviour of the older and newer commits, like git bisect's --term-old and --term-new.
+	// TermOld defaults to "good" and TermNew to "bad", or the other way around if Reverse is set.
+	TermOld string
+	TermNew string
+
+	// If set, only commits modifying at least one of these paths are bisected, like for git bisect start -- <paths>.
+	// The paths are relative to the repository's root.
+	Paths []string
+
+	// Whether to bisect the whole commit DAG between the good and bad commit like git bisect does, instead of only the first-parent history.
+	// The next commit to test is the one which best halves the remaining candidates, so merged branches don't have to be descended into separately.
+	// Cached builds are not preferred in this mode. Cannot be combined with probabilistic bisection.
+	FullHistory bool
+
+	// Verdicts which are already known before the bisection starts, by replica index, e.g. from a log read in using [Job.ParseBisectLog].
+	// They narrow down the replica's commits before any system is started.
+	// Verdicts of commits outside of the bisected first-parent history are mapped onto it: a good commit to its nearest ancestor, and a bad commit to the oldest commit containing it.
+	// Verdicts which can't be mapped, as well as those of commits outside of the bisected history of full history jobs, are ignored.
+	// If the job is resumed, the replica's checkpoint takes precedence.
+	KnownVerdicts [][]VerdictRecord
+
+	// Whether to descend into merged branches once a merge commit was found to be the offending commit. Defaults to always descending
+	MergePolicy MergePolicy
+
 	Dockerfile     string // The contents of the dockerfile.
 	DockerfilePath string // The path to the dockerfile relative to the present working directory. Only gets used if Dockerfile is empty.
 
 	Log *logrus.Logger // The log to which information gets printed to
 
-	MaxConcurrentReplicas uint // The max amount of replicas that can run concurrently, or 0 if no limit
+	MaxConcurrentReplicas uint // The max amount of systems that can run concurrently across all replicas, or 0 if no limit
 	replicaSemaphore      *semaphore.Weighted
 
+	// How many systems each replica tests in parallel. A replica with a parallelism of k splits the remaining commits into k+1 parts and tests all k commits in between at once.
+	// As verdicts arrive, systems whose commits became irrelevant are stopped, and verdicts for them are ignored.
+	// This reduces the amount of rounds needed from log_2(n) to log_(k+1)(n) at the cost of running more systems. Values below 2 result in one system being tested at a time.
+	Parallelism int
+
+	// Whether replicas should speculatively build the images of both possible next commits while waiting for a verdict.
+	// The build which turns out to be unneeded still populates the image cache for later bisections.
+	SpeculativeBuilds bool
+	// The max amount of speculative builds that can run concurrently across all replicas, or 0 if no limit.
+	// Speculative builds exceeding this limit are skipped.
+	MaxSpeculativeBuilds uint
+	speculativeSemaphore *semaphore.Weighted
+
 	dockerfileString string // The parsed dockerfile for building the repository
 	dockerfileHash   string // The hash of the dockerfile string, for differentiating them in built images
 
-	replicas []*replica // This job's replicas
+	replicas     []*replica // This job's replicas
+	replicasLock sync.Mutex // Lock guarding replicas, ReplicasCount, rsChan and ocChan once the job is running, since replicas can be added at runtime
+
+	addReplicaLock sync.Mutex // Lock serializing calls to AddReplica, which creates the new replica without holding replicasLock
+
+	rsChan chan RunningSystem   // The channel to which the systems of all replicas are sent
+	ocChan chan OffendingCommit // The channel to which the offending commits of all replicas are sent
 
 	Repository string // The repository URL
 	repoPath   string // The path to the original cloned repository which replicas will copy from
 
-	commits []string // This job's commits, where commits[0] is the good commit and commits[N-1] is the bad commit
+	ancestors []commitSet // The ancestors of each of this job's commits. Only set if the job bisects the full history
+	commits   []string    // This job's commits, where commits[0] is the old commit and commits[N-1] is the new commit. Unless the job is reversed, these are the good and bad commits respectively
 
-	builtImages map[string]bool // A hashmap where, if a commit exists as a key, this commit's docker image has already been built before
+	builtImages     map[string]bool // A hashmap where, if a commit exists as a key, this commit's docker image has already been built before
+	builtImagesLock sync.RWMutex    // Lock guarding builtImages, since images are built concurrently
 
 	imagesBuilding *sync.Map // Map of keys for every commit to ensure only one replica is building a specific commit at once
 
-	commitReplacements *sync.Map // Map of commits to the commits they should be replaced with. used to avoid commits that break the build
+	commitReplacements     *sync.Map  // Map of commits to the commits they should be replaced with. used to avoid commits that break the build
+	commitReplacementsLock sync.Mutex // Lock held while choosing and adding a replacement, such that concurrently added replacements can't form cycles
+	failedBuilds           *sync.Map  // Set of commits whose build failed. Only used in build mode, where failed builds are verdicts instead of being replaced
 
 	// Path to the file where commit replacements are written to and stored for subsequent runs. Defaults to "$(PWD)/.biscepter-replacements~"
 	CommitReplacementsBackup     string
 	commitReplacementsBackupFile *os.File
+
+	// Path to the file where the bisection state of all replicas is written to after every verdict, such that the job can be continued using [Job.Resume].
+	// Defaults to "$(PWD)/.biscepter-checkpoint~"
+	Checkpoint         string
+	checkpointReplicas map[int]replicaCheckpoint // The latest checkpoint of every replica, by replica index
+	checkpointLock     sync.Mutex                // Lock guarding checkpointReplicas and the checkpoint file
 }
 
 // Run the job. This initializes all the replicas and starts them. This function returns a [RunningSystem] channel and an [OffendingCommit] channel.
@@ -176,40 +344,67 @@ func (job *Job) Run() (chan RunningSystem, chan OffendingCommit, error) {
 		job.Host = "127.0.0.1"
 	}
 
+	if job.BuildRetries == nil {
+		buildRetries := 3
+		job.BuildRetries = &buildRetries
+	} else if *job.BuildRetries < 0 {
+		return nil, nil, fmt.Errorf("build retries %d are negative", *job.BuildRetries)
+	}
+
+	if err := job.setTerms(); err != nil {
+		return nil, nil, errors.Join(fmt.Errorf("invalid terms"), err)
+	}
+
+	if job.Probabilistic != nil {
+		if job.FullHistory {
+			return nil, nil, fmt.Errorf("probabilistic bisection cannot be combined with bisecting the full history")
+		}
+		if err := job.Probabilistic.validate(); err != nil {
+			return nil, nil, errors.Join(fmt.Errorf("invalid probabilistic config"), err)
+		}
+	}
+
+	if job.Verification != nil {
+		if err := job.Verification.validate(); err != nil {
+			return nil, nil, errors.Join(fmt.Errorf("invalid verification config"), err)
+		}
+	}
+
+	if job.Mode == BuildMode && job.VerdictCommand != "" {
+		return nil, nil, fmt.Errorf("a verdict command cannot be combined with build mode, as no containers are started")
+	}
+	if job.Mode == StartupMode {
+		if job.VerdictCommand != "" {
+			return nil, nil, fmt.Errorf("a verdict command cannot be combined with startup mode, as the healthchecks determine the verdict")
+		}
+		if len(job.Healthchecks) == 0 {
+			return nil, nil, fmt.Errorf("startup mode requires at least one healthcheck")
+		}
+	}
+
 	// Init the replica semaphore
 	if job.MaxConcurrentReplicas == 0 {
 		job.MaxConcurrentReplicas = math.MaxInt
 	}
 	job.replicaSemaphore = semaphore.NewWeighted(int64(job.MaxConcurrentReplicas))
 
+	// Init the speculative builds semaphore
+	if job.MaxSpeculativeBuilds == 0 {
+		job.MaxSpeculativeBuilds = math.MaxInt
+	}
+	job.speculativeSemaphore = semaphore.NewWeighted(int64(job.MaxSpeculativeBuilds))
+
 	// Init the sync maps
 	job.imagesBuilding = &sync.Map{}
 	job.commitReplacements = &sync.Map{}
+	job.failedBuilds = &sync.Map{}
 
-	// Read in the stored replacements
-	if job.CommitReplacementsBackup == "" {
-		job.CommitReplacementsBackup = ".biscepter-replacements~"
-	}
-	var err error
-	job.commitReplacementsBackupFile, err = os.OpenFile(job.CommitReplacementsBackup, os.O_APPEND|os.O_WRONLY|os.O_CREATE, 0644)
-	if err != nil {
-		return nil, nil, errors.Join(fmt.Errorf("couldn't get replacements backup"), err)
-	}
-	replacements, err := os.ReadFile(job.CommitReplacementsBackup)
-	if err != nil {
-		return nil, nil, errors.Join(fmt.Errorf("couldn't read replacements"), err)
+	if job.Checkpoint == "" {
+		job.Checkpoint = ".biscepter-checkpoint~"
 	}
-
-	replacementPairs := strings.Split(strings.TrimSuffix(string(replacements), ","), ",")
-	if replacementPairs[0] != "" {
-		for _, pair := range replacementPairs {
-			split := strings.Split(pair, ":")
-			if len(split) != 2 {
-				return nil, nil, fmt.Errorf("format of replacements file entry incorrect: %s", pair)
-			}
-			job.Log.Debugf("Adding replacement from replacements file: %s -> %s", split[0], split[1])
-			job.commitReplacements.Store(split[0], split[1])
-		}
+	// Replicas are only restored from checkpoints if the job is resumed
+	if job.checkpointReplicas == nil {
+		job.checkpointReplicas = make(map[int]replicaCheckpoint)
 	}
 
 	// Populate job.dockerfileBytes, depending on which values were present in the config
@@ -217,6 +412,12 @@ func (job *Job) Run() (chan RunningSystem, chan OffendingCommit, error) {
 		return nil, nil, err
 	}
 
+	// Read in the stored replacements, which are scoped to the dockerfile
+	err := job.readReplacements()
+	if err != nil {
+		return nil, nil, err
+	}
+
 	job.Log.Info("Cloning initial repository...")
 	// Clone repo
 	job.repoPath, err = os.MkdirTemp("", "biscepter")
@@ -227,23 +428,36 @@ func (job *Job) Run() (chan RunningSystem, chan OffendingCommit, error) {
 		return nil, nil, errors.Join(fmt.Errorf("git clone of repository %s at %s failed, output: %s", job.Repository, job.repoPath, out), err)
 	}
 
-	job.Log.Info("Checking good and bad commits...")
-	// Make sure there is a path from BadCommit to GoodCommit
-	cmd := exec.Command("git", "rev-list", "--reverse", "--first-parent", job.BadCommit)
-	cmd.Dir = job.repoPath
-	out, err := cmd.CombinedOutput()
-	if err != nil {
-		return nil, nil, errors.Join(fmt.Errorf("failed to get rev-list of bad commit %s, output: %s", job.BadCommit, out), err)
-	}
-	if !strings.Contains(string(out), job.GoodCommit) {
-		return nil, nil, fmt.Errorf("good commit %s cannot be reached from bad commit %s", job.GoodCommit, job.BadCommit)
+	job.Log.Infof("Checking %s and %s commits...", job.TermOld, job.TermNew)
+	// Make sure there is a path from the new commit to the old commit
+	oldCommit, newCommit := job.getOldAndNewCommits()
+	if job.FullHistory {
+		cmd := exec.Command("git", "merge-base", "--is-ancestor", oldCommit, newCommit)
+		cmd.Dir = job.repoPath
+		if out, err := cmd.CombinedOutput(); err != nil {
+			return nil, nil, errors.Join(fmt.Errorf("%s commit %s is no ancestor of %s commit %s, output: %s", job.TermOld, oldCommit, job.TermNew, newCommit, out), err)
+		}
+	} else {
+		cmd := exec.Command("git", "rev-list", "--reverse", "--first-parent", newCommit)
+		cmd.Dir = job.repoPath
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			return nil, nil, errors.Join(fmt.Errorf("failed to get rev-list of %s commit %s, output: %s", job.TermNew, newCommit, out), err)
+		}
+		if !strings.Contains(string(out), oldCommit) {
+			return nil, nil, fmt.Errorf("%s commit %s cannot be reached from %s commit %s", job.TermOld, oldCommit, job.TermNew, newCommit)
+		}
 	}
 
 	job.Log.Info("Getting all commits...")
 	// Get all commits
-	job.commits, err = getCommitsBetween(job.GoodCommit, job.BadCommit, job.repoPath)
+	if job.FullHistory {
+		job.commits, job.ancestors, err = getCommitGraphBetween(oldCommit, newCommit, job.repoPath, job.Paths)
+	} else {
+		job.commits, err = getCommitsBetween(oldCommit, newCommit, job.repoPath, job.Paths)
+	}
 	if err != nil {
-		return nil, nil, fmt.Errorf("couldn't get commits between %s and %s - %v", job.GoodCommit, job.BadCommit, err)
+		return nil, nil, fmt.Errorf("couldn't get commits between %s and %s - %v", oldCommit, newCommit, err)
 	}
 
 	job.Log.Info("Getting all built images...")
@@ -268,7 +482,7 @@ func (job *Job) Run() (chan RunningSystem, chan OffendingCommit, error) {
 	for _, image := range images {
 		for _, tag := range image.RepoTags {
 			logrus.Debugf("Adding new built repo tag: %s", tag)
-			job.builtImages[tag] = true
+			job.setImageBuilt(tag)
 		}
 	}
 	cli.Close()
@@ -278,6 +492,8 @@ func (job *Job) Run() (chan RunningSystem, chan OffendingCommit, error) {
 	// TODO: Don't hardcode channel size
 	rsChan, ocChan := make(chan RunningSystem, 100), make(chan OffendingCommit, 100)
 
+	job.replicasLock.Lock()
+	defer job.replicasLock.Unlock()
 	job.replicas = make([]*replica, job.ReplicasCount)
 
 	// Create all replicas
@@ -295,6 +511,33 @@ func (job *Job) Run() (chan RunningSystem, chan OffendingCommit, error) {
 			return nil, nil, errors.Join(fmt.Errorf("failed to create job replica"), err)
 		}
 
+		// Restore the replica's state if the job is resumed
+		if rc, ok := job.checkpointReplicas[i]; ok {
+			if err := job.replicas[i].restoreCheckpoint(rc); err != nil {
+				for j := range i + 1 {
+					if err := job.replicas[j].stop(); err != nil {
+						return nil, nil, err
+					}
+				}
+				return nil, nil, errors.Join(fmt.Errorf("failed to restore checkpoint of job replica %d", i), err)
+			}
+		} else if i < len(job.KnownVerdicts) {
+			job.replicas[i].seedVerdicts(job.KnownVerdicts[i])
+		}
+
+		if job.replicas[i].isCancelled {
+			// The replica was cancelled before the job was resumed
+			if err := job.replicas[i].stop(); err != nil {
+				job.Log.Warnf("Failed to stop cancelled replica %d - %v", i, err)
+			}
+			job.Log.Infof("Replica %d was cancelled, not starting it again", i)
+			ocChan <- OffendingCommit{
+				ReplicaIndex: i,
+				Cancelled:    true,
+			}
+			continue
+		}
+
 		// Start the created replica
 		if err = job.replicas[i].start(rsChan, ocChan); err != nil {
 			// Stop running replicas
@@ -307,11 +550,158 @@ func (job *Job) Run() (chan RunningSystem, chan OffendingCommit, error) {
 		}
 	}
 
+	job.rsChan, job.ocChan = rsChan, ocChan
 	return rsChan, ocChan, nil
 }
 
+// AddReplica creates and starts a new replica of a running job, e.g. to bisect an issue discovered while the job is already running.
+// The new replica gets the next free replica index, which is returned, and shares the job's repository, built images and commit replacements with the other replicas.
+// Its systems and offending commit are sent to the channels returned by [Job.Run].
+//
+// This method errors if the passed job hasn't yet been initialized using [Job.Run], or if it was stopped.
+func (job *Job) AddReplica() (int, error) {
+	// Only one replica is added at a time, such that the new replica's index stays free while it is created without holding replicasLock
+	job.addReplicaLock.Lock()
+	defer job.addReplicaLock.Unlock()
+
+	job.replicasLock.Lock()
+	rsChan, ocChan := job.rsChan, job.ocChan
+	index := len(job.replicas)
+	job.replicasLock.Unlock()
+
+	if rsChan == nil {
+		return 0, fmt.Errorf("job isn't running. Have you initialized the passed job yet?")
+	}
+
+	// Copying the repository may take a while, during which the other replicas have to stay accessible
+	rep, err := createJobReplica(job, index, fmt.Sprint(index))
+	if err != nil {
+		return 0, errors.Join(fmt.Errorf("failed to create job replica %d", index), err)
+	}
+	if index < len(job.KnownVerdicts) {
+		rep.seedVerdicts(job.KnownVerdicts[index])
+	}
+	if err := rep.start(rsChan, ocChan); err != nil {
+		if err := rep.stop(); err != nil {
+			return 0, errors.Join(fmt.Errorf("failed to stop job replica %d after its start failed", index), err)
+		}
+		return 0, errors.Join(fmt.Errorf("failed to start job replica %d", index), err)
+	}
+
+	job.replicasLock.Lock()
+	defer job.replicasLock.Unlock()
+
+	if job.rsChan == nil {
+		if err := rep.stop(); err != nil {
+			return 0, errors.Join(fmt.Errorf("failed to stop job replica %d after the job was stopped", index), err)
+		}
+		return 0, fmt.Errorf("job was stopped while adding replica %d", index)
+	}
+	job.replicas = append(job.replicas, rep)
+	job.ReplicasCount = len(job.replicas)
+	job.Log.Infof("Added replica %d", index)
+	return index, nil
+}
+
+// CancelReplica stops the replica with the passed index without affecting the other replicas, e.g. if its issue turned out to be a duplicate.
+// The replica's running systems are stopped and its repository copies removed.
+// Instead of its offending commit, an [OffendingCommit] with Cancelled set is sent to the channel returned by [Job.Run], so that consumers don't wait for it.
+// The cancellation is stored in the job's checkpoint, such that the replica isn't started again by [Job.Resume].
+//
+// This method errors if the passed job hasn't yet been initialized using [Job.Run], or if the replica already found its offending commit or was cancelled before.
+func (job *Job) CancelReplica(index int) error {
+	rep, err := job.getReplica(index)
+	if err != nil {
+		return err
+	}
+
+	if err := rep.cancel(); err != nil {
+		return errors.Join(fmt.Errorf("failed to cancel replica %d", index), err)
+	}
+	stopErr := rep.stop()
+	job.Log.Infof("Cancelled replica %d", index)
+
+	job.replicasLock.Lock()
+	ocChan := job.ocChan
+	job.replicasLock.Unlock()
+	if ocChan == nil {
+		// The job was stopped in the meantime
+		return stopErr
+	}
+	ocChan <- OffendingCommit{
+		ReplicaIndex: index,
+		Cancelled:    true,
+	}
+
+	if stopErr != nil {
+		return errors.Join(fmt.Errorf("failed to stop cancelled replica %d", index), stopErr)
+	}
+	return nil
+}
+
+// MarkCommit applies the passed verdict of the passed commit to the replica with the passed index, e.g. if the commit is already known to be bad from CI results.
+// This narrows down the replica's commits without building or running anything, and stops the replica's running systems whose commits became irrelevant.
+// The commit doesn't have to be among the replica's commits, in which case it is mapped like the commits of [Job.KnownVerdicts].
+//
+// This method errors if the passed job hasn't yet been initialized using [Job.Run], if the replica already finished its bisection,
+// or if the verdict can't be applied, e.g. since it contradicts the replica's previous verdicts.
+func (job *Job) MarkCommit(index int, commit string, verdict Verdict) error {
+	rep, err := job.getReplica(index)
+	if err != nil {
+		return err
+	}
+
+	if err := rep.mark(VerdictRecord{
+		Commit:    commit,
+		Verdict:   verdict,
+		Timestamp: time.Now(),
+	}); err != nil {
+		return errors.Join(fmt.Errorf("failed to mark commit %s as %s for replica %d", commit, job.TermOfVerdict(verdict), index), err)
+	}
+	return nil
+}
+
+// UndoVerdict rolls the bisection of the replica with the passed index back to before its last steps verdicts, e.g. if a system was rated wrongly by accident.
+// Verdicts applied using [Job.MarkCommit] and [Job.KnownVerdicts] can be undone as well, whereas verdicts received before the job was resumed can't.
+// The replica's running systems are stopped, and the commit of the oldest undone verdict is offered again in the next round of systems, reusing its built image.
+// If the replica already found its offending commit, it continues its bisection and sends another [OffendingCommit] once it finished.
+//
+// This method errors if the passed job hasn't yet been initialized using [Job.Run], or if the replica received less than steps verdicts which can be undone.
+func (job *Job) UndoVerdict(index int, steps int) error {
+	rep, err := job.getReplica(index)
+	if err != nil {
+		return err
+	}
+
+	if err := rep.undo(steps); err != nil {
+		return errors.Join(fmt.Errorf("failed to undo %d verdicts of replica %d", steps, index), err)
+	}
+	return nil
+}
+
+// getReplica returns the replica of the running job with the passed index
+func (job *Job) getReplica(index int) (*replica, error) {
+	job.replicasLock.Lock()
+	defer job.replicasLock.Unlock()
+
+	if job.ocChan == nil {
+		return nil, fmt.Errorf("job isn't running. Have you initialized the passed job yet?")
+	}
+	if index < 0 || index >= len(job.replicas) {
+		return nil, fmt.Errorf("invalid replica index passed - %d is not between 0 and %d, the amount of replicas", index, len(job.replicas))
+	}
+	return job.replicas[index], nil
+}
+
 // Stop the job and all running replicas.
+// Afterwards, no replicas can be added to or managed in the job anymore.
 func (j *Job) Stop() error {
+	j.replicasLock.Lock()
+	defer j.replicasLock.Unlock()
+
+	// No replicas can be added to a stopped job
+	j.rsChan, j.ocChan = nil, nil
+
 	for i, replica := range j.replicas {
 		j.Log.Infof("Shutting down replica %d", i)
 		if err := replica.stop(); err != nil {
@@ -365,7 +755,8 @@ func (j *Job) RunCommitByHash(commitHash string) (*RunningSystem, error) {
 		Repository:    j.Repository,
 		ReplicasCount: 0,
 
-		BuildCost: j.BuildCost,
+		BuildCost:    j.BuildCost,
+		BuildRetries: j.BuildRetries,
 
 		Host:         j.Host,
 		Ports:        j.Ports,
@@ -376,6 +767,7 @@ func (j *Job) RunCommitByHash(commitHash string) (*RunningSystem, error) {
 
 		// If the build breaks, we don't know the replacements, so just ignore
 		CommitReplacementsBackup: "/dev/null",
+		Checkpoint:               os.DevNull,
 
 		GoodCommit: commitHash,
 		BadCommit:  commitHash,
@@ -426,6 +818,28 @@ func (j *Job) parseDockerfile() error {
 	return nil
 }
 
+// DockerfileHash returns the hash of the job's dockerfile, which scopes built images and [CommitReplacement] entries to it
+func (j *Job) DockerfileHash() (string, error) {
+	if err := j.parseDockerfile(); err != nil {
+		return "", err
+	}
+	return j.dockerfileHash, nil
+}
+
+// isImageBuilt returns whether the passed image was already built before
+func (j *Job) isImageBuilt(image string) bool {
+	j.builtImagesLock.RLock()
+	defer j.builtImagesLock.RUnlock()
+	return j.builtImages[image]
+}
+
+// setImageBuilt marks the passed image as having been built before
+func (j *Job) setImageBuilt(image string) {
+	j.builtImagesLock.Lock()
+	defer j.builtImagesLock.Unlock()
+	j.builtImages[image] = true
+}
+
 // getDockerImageOfCommit returns the name with the tag of the docker image which built the passed commit
 func (j *Job) getDockerImageOfCommit(commit string) string {
 	return fmt.Sprintf("biscepter-%s:%s", commit, j.dockerfileHash)
@@ -4,6 +4,7 @@ package biscepter_test
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
@@ -215,8 +216,17 @@ CMD ./server
 	<-rsChan
 
 	// Make sure the commit replacement is set correctly
-	out, err := io.ReadAll(replacements)
-	assert.Equal(t, "03cdf844a180c44763e12f29901ab5f8d61444f3:22a405d30a6c8d3eb045062ac2be4cff57e30d29,", string(out), "Commit replacement set incorrectly")
+	var entry struct {
+		Commit      string `json:"commit"`
+		Replacement string `json:"replacement"`
+		Reason      string `json:"reason"`
+		Log         string `json:"log"`
+	}
+	assert.NoError(t, json.NewDecoder(replacements).Decode(&entry), "Failed to decode commit replacement")
+	assert.Equal(t, "03cdf844a180c44763e12f29901ab5f8d61444f3", entry.Commit, "Commit replacement set incorrectly")
+	assert.Equal(t, "22a405d30a6c8d3eb045062ac2be4cff57e30d29", entry.Replacement, "Commit replacement set incorrectly")
+	assert.NotEmpty(t, entry.Reason, "Reason of commit replacement missing")
+	assert.NotEmpty(t, entry.Log, "Build log of commit replacement missing")
 
 	os.Remove(replacements.Name())
 
@@ -267,14 +277,110 @@ CMD [[ $(git rev-parse HEAD) != "03cdf844a180c44763e12f29901ab5f8d61444f3" ]] &&
 	<-rsChan
 
 	// Make sure the commit replacement is set correctly
-	out, err := io.ReadAll(replacements)
-	assert.Equal(t, "03cdf844a180c44763e12f29901ab5f8d61444f3:22a405d30a6c8d3eb045062ac2be4cff57e30d29,", string(out), "Commit replacement set incorrectly")
+	var entry struct {
+		Commit      string `json:"commit"`
+		Replacement string `json:"replacement"`
+		Reason      string `json:"reason"`
+	}
+	assert.NoError(t, json.NewDecoder(replacements).Decode(&entry), "Failed to decode commit replacement")
+	assert.Equal(t, "03cdf844a180c44763e12f29901ab5f8d61444f3", entry.Commit, "Commit replacement set incorrectly")
+	assert.Equal(t, "22a405d30a6c8d3eb045062ac2be4cff57e30d29", entry.Replacement, "Commit replacement set incorrectly")
+	assert.Contains(t, entry.Reason, "healthcheck", "Reason of commit replacement set incorrectly")
 
 	os.Remove(replacements.Name())
 	job.Stop()
 	cleanupDocker(":00b975cbd39dbd1f1fb2010a7015792206dd562755262667a8c98d4f33427388")()
 }
 
+func TestBuildMode(t *testing.T) {
+	t.Parallel()
+
+	job := biscepter.Job{
+		Log:           logrus.StandardLogger(),
+		ReplicasCount: 1,
+
+		Mode: biscepter.BuildMode,
+
+		GoodCommit: "8ee0e2a3c12e324c1b5c41f7861e341d91692efb",
+		BadCommit:  "9b70eda4f3e48d5d906f99b570a16d5a979b0a99",
+
+		CommitReplacementsBackup: "/dev/null",
+		Checkpoint:               os.DevNull,
+
+		Dockerfile: `
+FROM golang:1.22.0-alpine
+WORKDIR /app
+RUN apk add git
+COPY . .
+RUN ! git merge-base --is-ancestor 03cdf844a180c44763e12f29901ab5f8d61444f3 HEAD
+`,
+
+		Repository: "https://github.com/DominicWuest/biscepter-test-repo.git",
+	}
+
+	// Run job whose build fails starting from commit 03cdf844a180c44763e12f29901ab5f8d61444f3
+	rsChan, ocChan, err := job.Run()
+	assert.NoError(t, err, "Failed to start job")
+
+	select {
+	case commit := <-ocChan:
+		assert.Equal(t, "03cdf844a180c44763e12f29901ab5f8d61444f3", commit.Commit, "Bisection returned wrong commit")
+	case system := <-rsChan:
+		assert.Failf(t, "Got a running system in build mode", "System running commit %s was sent out", system.Commit)
+	}
+
+	job.Stop()
+	cleanupDocker(":79d29fc06e741225892aa864a6be378b0c6b77f68b13140c7b6f0b28f126e93a")()
+}
+
+func TestStartupMode(t *testing.T) {
+	t.Parallel()
+
+	job := biscepter.Job{
+		Log:           logrus.StandardLogger(),
+		ReplicasCount: 1,
+
+		Mode: biscepter.StartupMode,
+
+		Healthchecks: []biscepter.Healthcheck{
+			{Port: 3333, CheckType: biscepter.HttpGet200, Data: "/1", Config: biscepter.HealthcheckConfig{Retries: 50, Backoff: 10 * time.Millisecond, MaxBackoff: 10 * time.Millisecond}},
+		},
+
+		GoodCommit: "8ee0e2a3c12e324c1b5c41f7861e341d91692efb",
+		BadCommit:  "9b70eda4f3e48d5d906f99b570a16d5a979b0a99",
+
+		CommitReplacementsBackup: "/dev/null",
+		Checkpoint:               os.DevNull,
+
+		Dockerfile: `
+FROM golang:1.22.0-alpine
+WORKDIR /app
+RUN apk add git
+COPY . .
+RUN go build -o server main.go
+CMD git merge-base --is-ancestor 03cdf844a180c44763e12f29901ab5f8d61444f3 HEAD && echo "failed to start" && exit 1 || ./server
+`,
+
+		Repository: "https://github.com/DominicWuest/biscepter-test-repo.git",
+	}
+
+	// Run job whose CMD fails starting from commit 03cdf844a180c44763e12f29901ab5f8d61444f3
+	rsChan, ocChan, err := job.Run()
+	assert.NoError(t, err, "Failed to start job")
+
+	select {
+	case commit := <-ocChan:
+		assert.Equal(t, "03cdf844a180c44763e12f29901ab5f8d61444f3", commit.Commit, "Bisection returned wrong commit")
+		assert.Contains(t, commit.ContainerLogs, "failed to start", "Container logs of offending commit missing")
+		assert.NotEmpty(t, commit.HealthcheckError, "Healthcheck error of offending commit missing")
+	case system := <-rsChan:
+		assert.Failf(t, "Got a running system in startup mode", "System running commit %s was sent out", system.Commit)
+	}
+
+	job.Stop()
+	cleanupDocker(":6d23fd7826fd0f97e63e68187543c8f17034ef0e165b3e958317bb863fc3d9cf")()
+}
+
 func TestRunCommitByOffset(t *testing.T) {
 	job := biscepter.Job{
 		Log:           logrus.StandardLogger(),
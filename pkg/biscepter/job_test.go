@@ -1,10 +1,16 @@
 package biscepter
 
 import (
+	"context"
+	"os"
+	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
 
+	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
+	"golang.org/x/sync/semaphore"
 )
 
 func TestGetJobFromConfig(t *testing.T) {
@@ -12,7 +18,16 @@ func TestGetJobFromConfig(t *testing.T) {
 repository: "repo"
 goodCommit: "goodCommit"
 badCommit: "badCommit"
+reverse: true
+termOld: "broken"
+termNew: "fixed"
+paths:
+  - "a"
+  - "b/c"
+fullHistory: true
 buildCost: 42.25
+buildRetries: 0
+parallelism: 3
 ports:
   - 80
   - 443
@@ -21,20 +36,55 @@ healthcheck:
     type: http
     data: "/status"
 dockerfile: "dockerfile"
+probabilistic:
+  falseGoodRate: 0.25
+verification: {}
 `
 
 	job, err := GetJobFromConfig(strings.NewReader(yml))
 	assert.Nil(t, err, "GetJobFromConfig returned an error")
 
 	assert.Equal(t, 42.25, job.BuildCost, "Mismatch in job field")
+	if assert.NotNil(t, job.BuildRetries, "Disabled build retries weren't set") {
+		assert.Equal(t, 0, *job.BuildRetries, "Mismatch in job field")
+	}
+	assert.Equal(t, 3, job.Parallelism, "Mismatch in job field")
 	assert.ElementsMatch(t, []int{80, 443}, job.Ports, "Mismatch in job field")
 	assert.Equal(t, "goodCommit", job.GoodCommit, "Mismatch in job field")
 	assert.Equal(t, "badCommit", job.BadCommit, "Mismatch in job field")
+	assert.Equal(t, true, job.Reverse, "Mismatch in job field")
+	assert.Equal(t, "broken", job.TermOld, "Mismatch in job field")
+	assert.Equal(t, "fixed", job.TermNew, "Mismatch in job field")
+	assert.Equal(t, []string{"a", "b/c"}, job.Paths, "Mismatch in job field")
+	assert.Equal(t, true, job.FullHistory, "Mismatch in job field")
+	assert.Equal(t, 1, job.Verification.Runs, "Mismatch in job field")
+	assert.Equal(t, 3, job.Verification.MaxRestarts, "Mismatch in job field")
 	assert.Equal(t, "dockerfile", job.Dockerfile, "Mismatch in job field")
 	assert.Equal(t, "repo", job.Repository, "Mismatch in job field")
 	assert.Equal(t, 1234, job.Healthchecks[0].Port, "Mismatch in job field")
 	assert.Equal(t, HttpGet200, job.Healthchecks[0].CheckType, "Mismatch in job field")
 	assert.Equal(t, "/status", job.Healthchecks[0].Data, "Mismatch in job field")
+	assert.Equal(t, 0.25, job.Probabilistic.FalseGoodRate, "Mismatch in job field")
+	assert.Equal(t, 0.95, job.Probabilistic.Confidence, "Mismatch in job field")
+}
+
+func TestGetJobFromConfigVerdict(t *testing.T) {
+	yml := `
+repository: "repo"
+goodCommit: "goodCommit"
+badCommit: "badCommit"
+verdict: "./run-tests.sh"
+dockerfile: "dockerfile"
+`
+
+	job, err := GetJobFromConfig(strings.NewReader(yml))
+	assert.Nil(t, err, "GetJobFromConfig returned an error for a job with a verdict command but without ports")
+
+	assert.Equal(t, "./run-tests.sh", job.VerdictCommand, "Mismatch in job field")
+	assert.Empty(t, job.Ports, "Mismatch in job field")
+
+	_, err = GetJobFromConfig(strings.NewReader(strings.ReplaceAll(yml, `verdict: "./run-tests.sh"`, "")))
+	assert.Error(t, err, "GetJobFromConfig didn't return an error for a job without verdict command and ports")
 }
 
 func TestGetDockerImageOfCommit(t *testing.T) {
@@ -56,3 +106,146 @@ func TestGetDockerImageOfCommit(t *testing.T) {
 		assert.Equal(t, v.image, job.getDockerImageOfCommit(v.commit), "Wrong docker image")
 	}
 }
+
+func TestAddReplicaNotRunning(t *testing.T) {
+	job := &Job{}
+	_, err := job.AddReplica()
+	assert.Error(t, err, "AddReplica didn't return an error for a job which isn't running")
+	assert.Empty(t, job.replicas, "AddReplica added a replica to a job which isn't running")
+}
+
+func TestAddReplicaStopped(t *testing.T) {
+	job, _, commits := newRunningTestJob(t)
+	job.rsChan = make(chan RunningSystem)
+
+	assert.NoError(t, job.Stop(), "Stop returned an error")
+	_, err := job.AddReplica()
+	assert.Error(t, err, "AddReplica didn't return an error for a stopped job")
+	assert.Len(t, job.replicas, 1, "AddReplica added a replica to a stopped job")
+	assert.Error(t, job.MarkCommit(0, commits[1], Good), "No error when marking a commit of a stopped job")
+}
+
+func TestCancelReplica(t *testing.T) {
+	newReplica := func(index int) *replica {
+		return &replica{
+			index:         index,
+			repoCopies:    []string{t.TempDir()},
+			activeSystems: make(map[string]*RunningSystem),
+			waitingCond:   sync.NewCond(&sync.Mutex{}),
+			log:           logrus.NewEntry(logrus.StandardLogger()),
+		}
+	}
+	job := &Job{
+		Log:    logrus.StandardLogger(),
+		ocChan: make(chan OffendingCommit, 1),
+
+		TermOld: "good",
+		TermNew: "bad",
+
+		Checkpoint:         filepath.Join(t.TempDir(), "checkpoint"),
+		checkpointReplicas: make(map[int]replicaCheckpoint),
+	}
+	job.replicas = []*replica{newReplica(0), newReplica(1)}
+	for _, rep := range job.replicas {
+		rep.parentJob = job
+	}
+	job.replicas[1].isFinished = true
+
+	assert.NoError(t, job.CancelReplica(0), "CancelReplica returned an error")
+	assert.True(t, job.replicas[0].isStopped, "Cancelled replica wasn't stopped")
+	assert.NoDirExists(t, job.replicas[0].repoCopies[0], "Repo copy of cancelled replica wasn't removed")
+	assert.Equal(t, OffendingCommit{ReplicaIndex: 0, Cancelled: true}, <-job.ocChan, "Wrong offending commit of cancelled replica")
+	cp, err := job.readCheckpoint()
+	assert.NoError(t, err, "Failed to read checkpoint")
+	assert.Len(t, cp.Replicas, 1, "Wrong amount of replicas in checkpoint")
+	assert.True(t, cp.Replicas[0].Cancelled, "Cancellation wasn't stored in checkpoint")
+
+	assert.Error(t, job.CancelReplica(0), "No error when cancelling a replica twice")
+	assert.Error(t, job.CancelReplica(1), "No error when cancelling a finished replica")
+	assert.Error(t, job.CancelReplica(2), "No error when cancelling a non-existent replica")
+	assert.Error(t, (&Job{}).CancelReplica(0), "No error when cancelling a replica of a job which isn't running")
+}
+
+// newRunningTestJob returns a running job with a single replica bisecting a test repository with six commits.
+// The replica is testing the commit with offset 3, but its goroutine isn't started.
+func newRunningTestJob(t *testing.T) (*Job, *replica, []string) {
+	repoPath, commits := createTestRepo(t, []string{"a", "b", "c", "d", "e", "f"})
+
+	job := &Job{
+		Log:              logrus.StandardLogger(),
+		TermOld:          "good",
+		TermNew:          "bad",
+		Checkpoint:       os.DevNull,
+		replicaSemaphore: semaphore.NewWeighted(1),
+		ocChan:           make(chan OffendingCommit),
+	}
+	rep := &replica{
+		parentJob:        job,
+		repoPath:         repoPath,
+		commits:          commits,
+		goodCommitOffset: 0,
+		badCommitOffset:  len(commits) - 1,
+		skippedCommits:   make(map[int]bool),
+		activeSystems:    make(map[string]*RunningSystem),
+		waitingCond:      sync.NewCond(&sync.Mutex{}),
+		log:              logrus.NewEntry(logrus.StandardLogger()),
+	}
+	job.replicas = []*replica{rep}
+
+	job.replicaSemaphore.Acquire(context.Background(), 1)
+	rep.activeSystems["system"] = &RunningSystem{containerName: "system", commitRootOffset: 3}
+	rep.pendingSystems = 1
+
+	return job, rep, commits
+}
+
+func TestMarkCommit(t *testing.T) {
+	job, rep, commits := newRunningTestJob(t)
+
+	assert.NoError(t, job.MarkCommit(0, commits[1], Good), "MarkCommit returned an error")
+	assert.Equal(t, 1, rep.goodCommitOffset, "Good commit wasn't applied")
+	assert.Len(t, rep.activeSystems, 1, "Relevant system was stopped")
+
+	assert.NoError(t, job.MarkCommit(0, commits[2][:8], Bad), "MarkCommit returned an error for an abbreviated hash")
+	assert.Equal(t, 2, rep.badCommitOffset, "Bad commit wasn't applied")
+	assert.Empty(t, rep.activeSystems, "Irrelevant system wasn't stopped")
+	assert.Equal(t, 0, rep.pendingSystems, "Irrelevant system wasn't released")
+	assert.Equal(t, commits[2], rep.verdicts[1].Commit, "Verdict wasn't recorded with the full hash")
+
+	assert.Error(t, job.MarkCommit(0, commits[4], Good), "No error for a contradicting verdict")
+	assert.Error(t, job.MarkCommit(0, "doesnotexist", Bad), "No error for a non-existent commit")
+	assert.Error(t, job.MarkCommit(1, commits[1], Bad), "No error for a non-existent replica")
+	assert.Len(t, rep.verdicts, 2, "Failed verdicts were recorded")
+
+	rep.isFinished = true
+	assert.Error(t, job.MarkCommit(0, commits[1], Good), "No error for a finished replica")
+}
+
+func TestUndoVerdict(t *testing.T) {
+	job, rep, commits := newRunningTestJob(t)
+
+	assert.NoError(t, job.MarkCommit(0, commits[1], Good), "MarkCommit returned an error")
+	assert.NoError(t, job.MarkCommit(0, commits[4], Bad), "MarkCommit returned an error")
+	assert.NoError(t, job.MarkCommit(0, commits[2], Skip), "MarkCommit returned an error")
+	assert.Len(t, rep.activeSystems, 1, "Relevant system was stopped")
+
+	assert.NoError(t, job.UndoVerdict(0, 1), "UndoVerdict returned an error")
+	assert.Empty(t, rep.skippedCommits, "Skip verdict wasn't undone")
+	assert.Empty(t, rep.activeSystems, "Running system wasn't stopped")
+	assert.Equal(t, 0, rep.pendingSystems, "Running system wasn't released")
+
+	assert.NoError(t, job.UndoVerdict(0, 1), "UndoVerdict returned an error")
+	assert.Equal(t, 1, rep.goodCommitOffset, "Wrong good commit offset after undoing")
+	assert.Equal(t, 5, rep.badCommitOffset, "Bad verdict wasn't undone")
+	assert.Len(t, rep.verdicts, 1, "Undone verdicts weren't dropped")
+	assert.Equal(t, 3, rep.getNextCommit(), "Wrong next commit after undoing")
+
+	assert.NoError(t, job.MarkCommit(0, commits[3], Bad), "MarkCommit returned an error after undoing")
+	assert.Error(t, job.UndoVerdict(0, 3), "No error when undoing more verdicts than received")
+	assert.Error(t, job.UndoVerdict(0, 0), "No error when undoing no verdicts")
+
+	assert.NoError(t, job.UndoVerdict(0, 2), "UndoVerdict returned an error")
+	assert.Equal(t, 0, rep.goodCommitOffset, "Wrong good commit offset after undoing all verdicts")
+	assert.Equal(t, 5, rep.badCommitOffset, "Wrong bad commit offset after undoing all verdicts")
+	assert.Empty(t, rep.verdicts, "Undone verdicts weren't dropped")
+}
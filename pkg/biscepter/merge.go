@@ -0,0 +1,107 @@
+package biscepter
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// mergePolicyYaml is either one of the scalars "always" and "never", or a mapping containing maxDepth
+type mergePolicyYaml struct {
+	Policy   string
+	MaxDepth int `yaml:"maxDepth"`
+}
+
+func (m *mergePolicyYaml) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		return value.Decode(&m.Policy)
+	}
+
+	var policy struct {
+		MaxDepth *int `yaml:"maxDepth"`
+	}
+	if err := value.Decode(&policy); err != nil {
+		return err
+	}
+	if policy.MaxDepth == nil {
+		return fmt.Errorf("merge policy mapping at line %d does not contain maxDepth", value.Line)
+	}
+	m.Policy = "maxDepth"
+	m.MaxDepth = *policy.MaxDepth
+	return nil
+}
+
+// toMergePolicy converts the yaml merge policy to a MergePolicy
+func (m mergePolicyYaml) toMergePolicy() (MergePolicy, error) {
+	policyTypes := map[string]MergePolicyType{
+		"":         MergeAlways,
+		"always":   MergeAlways,
+		"never":    MergeNever,
+		"maxDepth": MergeMaxDepth,
+	}
+	policyType, ok := policyTypes[m.Policy]
+	if !ok {
+		return MergePolicy{}, fmt.Errorf("invalid merge policy %q supplied", m.Policy)
+	}
+	if m.MaxDepth < 0 {
+		return MergePolicy{}, fmt.Errorf("merge policy max depth %d is negative", m.MaxDepth)
+	}
+	return MergePolicy{
+		Type:     policyType,
+		MaxDepth: m.MaxDepth,
+	}, nil
+}
+
+// MergePolicyType specifies whether a replica descends into the merged branch once it found a merge commit to be the offending commit
+type MergePolicyType int
+
+const (
+	// Always descend into merged branches, no matter how deeply they are nested. This is the default
+	MergeAlways MergePolicyType = iota
+	// Never descend into merged branches, reporting the offending merge commit instead
+	MergeNever
+	// Descend into at most MaxDepth nested merged branches
+	MergeMaxDepth
+)
+
+// The MergePolicy struct determines how deep replicas descend into merged branches.
+// Whenever a replica stops at an offending merge commit, its OffendingCommit lists the commits merged by it.
+type MergePolicy struct {
+	Type     MergePolicyType // The type of the merge policy
+	MaxDepth int             // The max amount of nested merged branches to descend into. Only used if Type is MergeMaxDepth
+}
+
+// allowsDescent returns whether a replica which already descended into the passed amount of merged branches may descend into another one
+func (p MergePolicy) allowsDescent(depth int) bool {
+	switch p.Type {
+	case MergeNever:
+		return false
+	case MergeMaxDepth:
+		return depth < p.MaxDepth
+	default:
+		return true
+	}
+}
+
+// getMergedCommits returns the hashes of all commits merged by the passed commit, i.e. the commits reachable from any of its parents but not from its first parent.
+// If any paths are passed, only commits modifying at least one of them are returned.
+// The returned slice is ordered chronologically and empty if the commit is no merge commit.
+func getMergedCommits(commitHash, repoPath string, paths []string) ([]string, error) {
+	args := []string{"rev-list", "--reverse", "^" + commitHash + "^1", commitHash + "^@"}
+	if len(paths) != 0 {
+		args = append(append(args, "--"), paths...)
+	}
+	cmd := exec.Command("git", args...)
+	cmd.Dir = repoPath
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return nil, errors.Join(fmt.Errorf("failed to get commits merged by %s, output: %s", commitHash, out), err)
+	}
+	if len(out) == 0 {
+		return []string{}, nil
+	}
+	return strings.Split(string(out[:len(out)-1]), "\n"), nil
+}
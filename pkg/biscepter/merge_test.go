@@ -0,0 +1,80 @@
+package biscepter
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetJobFromConfigMergePolicy(t *testing.T) {
+	values := []struct {
+		policy string
+
+		expected MergePolicy
+		err      bool
+	}{
+		{"", MergePolicy{Type: MergeAlways}, false},
+		{"mergePolicy: always", MergePolicy{Type: MergeAlways}, false},
+		{"mergePolicy: never", MergePolicy{Type: MergeNever}, false},
+		{"mergePolicy:\n  maxDepth: 2", MergePolicy{Type: MergeMaxDepth, MaxDepth: 2}, false},
+		{"mergePolicy: sometimes", MergePolicy{}, true},
+		{"mergePolicy:\n  maxDepth: -1", MergePolicy{}, true},
+		{"mergePolicy:\n  depth: 1", MergePolicy{}, true},
+	}
+
+	for _, v := range values {
+		yml := "repository: \"repo\"\nport: 80\n" + v.policy + "\n"
+		job, err := GetJobFromConfig(strings.NewReader(yml))
+		if v.err {
+			assert.Errorf(t, err, "Merge policy %q didn't raise an error", v.policy)
+		} else {
+			assert.NoErrorf(t, err, "Merge policy %q raised an error", v.policy)
+			assert.Equalf(t, v.expected, job.MergePolicy, "Wrong merge policy for %q", v.policy)
+		}
+	}
+}
+
+func TestMergePolicyAllowsDescent(t *testing.T) {
+	values := []struct {
+		policy MergePolicy
+		depth  int
+
+		expected bool
+	}{
+		{MergePolicy{Type: MergeAlways}, 0, true},
+		{MergePolicy{Type: MergeAlways}, 100, true},
+		{MergePolicy{Type: MergeNever}, 0, false},
+		{MergePolicy{Type: MergeMaxDepth, MaxDepth: 2}, 0, true},
+		{MergePolicy{Type: MergeMaxDepth, MaxDepth: 2}, 1, true},
+		{MergePolicy{Type: MergeMaxDepth, MaxDepth: 2}, 2, false},
+		{MergePolicy{Type: MergeMaxDepth, MaxDepth: 0}, 0, false},
+	}
+
+	for _, v := range values {
+		assert.Equalf(t, v.expected, v.policy.allowsDescent(v.depth), "Wrong result for policy %+v at depth %d", v.policy, v.depth)
+	}
+}
+
+func TestGetMergedCommits(t *testing.T) {
+	repoPath, _ := createTestRepo(t, []string{"base"})
+	runGit(t, repoPath, "checkout", "-q", "-b", "feature")
+	f1 := commitFile(t, repoPath, "f1")
+	f2 := commitFile(t, repoPath, "f2")
+	runGit(t, repoPath, "checkout", "-q", "main")
+	commitFile(t, repoPath, "main")
+	runGit(t, repoPath, "merge", "-q", "--no-ff", "-m", "merge", "feature")
+	merge := runGit(t, repoPath, "rev-parse", "HEAD")
+
+	mergedCommits, err := getMergedCommits(merge, repoPath, nil)
+	assert.NoError(t, err, "getMergedCommits returned an error")
+	assert.Equal(t, []string{f1, f2}, mergedCommits, "Wrong merged commits")
+
+	mergedCommits, err = getMergedCommits(merge, repoPath, []string{"f2"})
+	assert.NoError(t, err, "getMergedCommits returned an error for paths")
+	assert.Equal(t, []string{f2}, mergedCommits, "Wrong merged commits for paths")
+
+	mergedCommits, err = getMergedCommits(f2, repoPath, nil)
+	assert.NoError(t, err, "getMergedCommits returned an error for a regular commit")
+	assert.Empty(t, mergedCommits, "Regular commit merged commits")
+}
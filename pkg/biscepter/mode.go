@@ -0,0 +1,48 @@
+package biscepter
+
+import (
+	"fmt"
+	"strings"
+)
+
+// A BisectionMode determines what the verdict of a commit is based on
+type BisectionMode int
+
+const (
+	// Systems running the commits are started and sent out to be tested, or rated by the job's verdict command. This is the default.
+	// Commits breaking the build are replaced by the closest commit of the remaining window which builds, or skipped if there is none
+	RunMode BisectionMode = iota
+	// Only the image of every commit is built, without starting any containers. A failed build is a bad verdict and a successful build a good one,
+	// so that the commit which broke the build is found instead of being replaced
+	BuildMode
+	// Systems running the commits are started, but not sent out to be tested. A failed healthcheck after a successful build is a bad verdict
+	// and passing all healthchecks a good one, so that the commit which broke the startup is found instead of being replaced.
+	// The last healthcheck error and the container's logs are reported alongside the offending commit
+	StartupMode
+)
+
+func (m BisectionMode) String() string {
+	switch m {
+	case BuildMode:
+		return "build"
+	case StartupMode:
+		return "startup"
+	default:
+		return "run"
+	}
+}
+
+// toBisectionMode converts the passed mode of a job config to a BisectionMode, where an empty mode is the default RunMode
+func toBisectionMode(mode string) (BisectionMode, error) {
+	modes := map[string]BisectionMode{
+		"":        RunMode,
+		"run":     RunMode,
+		"build":   BuildMode,
+		"startup": StartupMode,
+	}
+	bisectionMode, ok := modes[strings.ToLower(mode)]
+	if !ok {
+		return RunMode, fmt.Errorf("invalid bisection mode %q supplied", mode)
+	}
+	return bisectionMode, nil
+}
@@ -0,0 +1,69 @@
+package biscepter
+
+import (
+	"os"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetJobFromConfigMode(t *testing.T) {
+	values := []struct {
+		config string
+
+		expected BisectionMode
+		err      bool
+	}{
+		{"port: 80", RunMode, false},
+		{"port: 80\nmode: run", RunMode, false},
+		{"mode: build", BuildMode, false},
+		{"port: 80\nmode: Build", BuildMode, false},
+		{"mode: run", RunMode, true},
+		{"port: 80\nmode: startup", StartupMode, false},
+		{"mode: startup\nhealthcheck:\n  - port: 80\n    type: http", StartupMode, false},
+		{"port: 80\nmode: boot", RunMode, true},
+	}
+
+	for _, v := range values {
+		yml := "repository: \"repo\"\n" + v.config + "\n"
+		job, err := GetJobFromConfig(strings.NewReader(yml))
+		if v.err {
+			assert.Errorf(t, err, "Config %q didn't raise an error", v.config)
+		} else {
+			assert.NoErrorf(t, err, "Config %q raised an error", v.config)
+			assert.Equalf(t, v.expected, job.Mode, "Wrong mode for %q", v.config)
+		}
+	}
+}
+
+func TestStartupFailureReported(t *testing.T) {
+	repoPath, commits := createTestRepo(t, []string{"a", "b", "c"})
+
+	rep := &replica{
+		parentJob: &Job{
+			Mode:               StartupMode,
+			TermOld:            "good",
+			TermNew:            "bad",
+			Checkpoint:         os.DevNull,
+			commitReplacements: &sync.Map{},
+		},
+		repoPath:         repoPath,
+		commits:          commits,
+		goodCommitOffset: 1,
+		badCommitOffset:  2,
+		skippedCommits:   make(map[int]bool),
+		startupFailures: map[string]startupFailure{
+			commits[1]: {healthcheckError: "older error", containerLogs: "older logs"},
+			commits[2]: {healthcheckError: "healthcheck on port 80 failed", containerLogs: "panic: boom"},
+		},
+		log: logrus.NewEntry(logrus.StandardLogger()),
+	}
+
+	oc := rep.getOffendingCommit()
+	assert.NotNil(t, oc, "Offending commit wasn't reported")
+	assert.Equal(t, "healthcheck on port 80 failed", oc.HealthcheckError, "Wrong healthcheck error")
+	assert.Equal(t, "panic: boom", oc.ContainerLogs, "Wrong container logs")
+}
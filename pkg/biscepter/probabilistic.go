@@ -0,0 +1,175 @@
+package biscepter
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+type probabilisticYaml struct {
+	FalseGoodRate float64 `yaml:"falseGoodRate"`
+	FalseBadRate  float64 `yaml:"falseBadRate"`
+
+	Confidence float64 `yaml:"confidence" default:"0.95"`
+}
+
+// ProbabilisticConfig configures the noise-tolerant bisection of flaky issues.
+//
+// Instead of treating every verdict as certain, replicas keep a probability distribution over which commit is the offending one.
+// Every verdict updates this distribution according to the configured error rates, the same commit may be tested multiple times,
+// and the next commit to test is the one with the highest expected information gain.
+// The bisection finishes once a single commit is the offending commit with a probability of at least Confidence.
+// For reversed jobs, "exhibiting the issue" refers to showing the behaviour of the new commit, i.e. being fixed.
+type ProbabilisticConfig struct {
+	FalseGoodRate float64 // The probability of a commit exhibiting the issue being rated as good, e.g. because the issue did not trigger during the test
+	FalseBadRate  float64 // The probability of a commit not exhibiting the issue being rated as bad
+
+	Confidence float64 // The probability with which a commit has to be the offending commit for the bisection to finish. Defaults to 0.95, and has to be below 1 if either error rate is non-zero
+}
+
+// validate checks whether the config's values are valid probabilities and sets the default confidence if none was set
+func (c *ProbabilisticConfig) validate() error {
+	if c.Confidence == 0 {
+		c.Confidence = 0.95
+	}
+
+	if c.FalseGoodRate < 0 || c.FalseGoodRate >= 1 {
+		return fmt.Errorf("false good rate %f is not within [0, 1)", c.FalseGoodRate)
+	}
+	if c.FalseBadRate < 0 || c.FalseBadRate >= 1 {
+		return fmt.Errorf("false bad rate %f is not within [0, 1)", c.FalseBadRate)
+	}
+	if c.Confidence < 0 || c.Confidence > 1 {
+		return fmt.Errorf("confidence %f is not within (0, 1]", c.Confidence)
+	}
+	if c.Confidence == 1 && (c.FalseGoodRate > 0 || c.FalseBadRate > 0) {
+		// No commit can ever be the offending commit with certainty if verdicts may be wrong
+		return fmt.Errorf("confidence of 1 can't be reached with non-zero error rates")
+	}
+	return nil
+}
+
+// initPosterior sets this replica's posterior to the uniform distribution over all commits between the good and bad commit, including the bad commit.
+// posterior[i] is the probability of commit i being the offending commit, meaning posterior[0] is always zero.
+func (r *replica) initPosterior() {
+	r.posterior = make([]float64, len(r.commits))
+	for i := 1; i < len(r.commits); i++ {
+		r.posterior[i] = 1 / float64(len(r.commits)-1)
+	}
+}
+
+// updatePosterior updates this replica's posterior using Bayes' theorem after the commit with the passed offset received the passed verdict.
+// A commit exhibits the issue if its offset is greater than or equal to the offset of the offending commit.
+func (r *replica) updatePosterior(commitOffset int, verdict Verdict) {
+	config := r.parentJob.Probabilistic
+
+	// Likelihoods of the verdict given the commit does or does not exhibit the issue
+	var likelihoodBad, likelihoodGood float64
+	switch verdict {
+	case Good:
+		likelihoodBad, likelihoodGood = config.FalseGoodRate, 1-config.FalseBadRate
+	case Bad:
+		likelihoodBad, likelihoodGood = 1-config.FalseGoodRate, config.FalseBadRate
+	default:
+		return
+	}
+
+	updated := make([]float64, len(r.posterior))
+	sum := 0.0
+	for i, p := range r.posterior {
+		if i <= commitOffset {
+			updated[i] = p * likelihoodBad
+		} else {
+			updated[i] = p * likelihoodGood
+		}
+		sum += updated[i]
+	}
+
+	if sum == 0 {
+		r.log.Warnf("Verdict %s of commit with offset %d is impossible given the previous verdicts and error rates, ignoring it", verdict, commitOffset)
+		return
+	}
+
+	for i := range updated {
+		updated[i] /= sum
+	}
+	r.posterior = updated
+}
+
+// getMostLikelyCommit returns the offset of the commit which is most likely to be the offending commit, as well as said probability
+func (r replica) getMostLikelyCommit() (int, float64) {
+	best := 0
+	for i, p := range r.posterior {
+		if p > r.posterior[best] {
+			best = i
+		}
+	}
+	return best, r.posterior[best]
+}
+
+// getMostInformativeCommit returns the offset of the untested commit whose verdict is expected to reduce the entropy of the posterior the most.
+// If every commit was skipped, -1 is returned.
+func (r replica) getMostInformativeCommit() int {
+	commitOffsets := r.getMostInformativeCommits(1)
+	if len(commitOffsets) == 0 {
+		return -1
+	}
+
+	mostLikely, probability := r.getMostLikelyCommit()
+	r.log.Infof("Most likely offending commit has offset %d with probability %.3f, next commit %d", mostLikely, probability, commitOffsets[0])
+	return commitOffsets[0]
+}
+
+// getMostInformativeCommits returns the offsets of at most count untested commits whose verdicts are expected to reduce the entropy of the posterior the most,
+// ordered by their expected information gain.
+//
+// The expected entropy of testing commit k is computed in constant time per commit using the prefix sums
+//
+//	S_k = sum_{i <= k} p_i
+//	T_k = sum_{i <= k} p_i * log(p_i)
+//
+// since, for a verdict with likelihoods a for i <= k and b for i > k and the normalization Z = a * S_k + b * (1 - S_k),
+//
+//	Z * H(posterior | verdict) = Z * log(Z) - (a * log(a) * S_k + a * T_k + b * log(b) * (1 - S_k) + b * (T_N - T_k))
+func (r replica) getMostInformativeCommits(count int) []int {
+	config := r.parentJob.Probabilistic
+
+	total := 0.0
+	for _, p := range r.posterior {
+		total += xLogX(p)
+	}
+
+	// Weighted entropy of the posterior after receiving a verdict with the passed likelihoods
+	weightedEntropy := func(a, b, s, t float64) float64 {
+		z := a*s + b*(1-s)
+		return xLogX(z) - (xLogX(a)*s + a*t + xLogX(b)*(1-s) + b*(total-t))
+	}
+
+	commitOffsets := []int{}
+	expectedEntropies := make(map[int]float64)
+	s, t := 0.0, 0.0
+	for k := 0; k < len(r.posterior)-1; k++ {
+		s += r.posterior[k]
+		t += xLogX(r.posterior[k])
+
+		if !r.isUntested(k) {
+			continue
+		}
+
+		commitOffsets = append(commitOffsets, k)
+		expectedEntropies[k] = weightedEntropy(1-config.FalseGoodRate, config.FalseBadRate, s, t) + weightedEntropy(config.FalseGoodRate, 1-config.FalseBadRate, s, t)
+	}
+
+	sort.SliceStable(commitOffsets, func(i, j int) bool {
+		return expectedEntropies[commitOffsets[i]] < expectedEntropies[commitOffsets[j]]
+	})
+	return commitOffsets[:min(count, len(commitOffsets))]
+}
+
+// xLogX returns x * log(x), where 0 * log(0) is defined as 0
+func xLogX(x float64) float64 {
+	if x <= 0 {
+		return 0
+	}
+	return x * math.Log(x)
+}
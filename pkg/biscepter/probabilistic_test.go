@@ -0,0 +1,114 @@
+package biscepter
+
+import (
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func newProbabilisticReplica(commits int, config ProbabilisticConfig) *replica {
+	rep := &replica{
+		goodCommitOffset: 0,
+		badCommitOffset:  commits - 1,
+		commits:          make([]string, commits),
+		skippedCommits:   make(map[int]bool),
+		log:              logrus.NewEntry(logrus.StandardLogger()),
+		parentJob: &Job{
+			Probabilistic: &config,
+		},
+	}
+	rep.initPosterior()
+	return rep
+}
+
+func TestUpdatePosterior(t *testing.T) {
+	t.Run("Certain verdicts eliminate commits", func(t *testing.T) {
+		rep := newProbabilisticReplica(5, ProbabilisticConfig{})
+
+		rep.updatePosterior(2, Good)
+		assert.InDeltaSlice(t, []float64{0, 0, 0, 0.5, 0.5}, rep.posterior, 1e-9, "Good verdict resulted in wrong posterior")
+
+		rep.updatePosterior(3, Bad)
+		assert.InDeltaSlice(t, []float64{0, 0, 0, 1, 0}, rep.posterior, 1e-9, "Bad verdict resulted in wrong posterior")
+	})
+
+	t.Run("Noisy verdicts shift probabilities", func(t *testing.T) {
+		rep := newProbabilisticReplica(3, ProbabilisticConfig{FalseGoodRate: 0.5})
+
+		// P(good | c = 1) = 0.5, P(good | c = 2) = 1
+		rep.updatePosterior(1, Good)
+		assert.InDeltaSlice(t, []float64{0, 1.0 / 3, 2.0 / 3}, rep.posterior, 1e-9, "Noisy good verdict resulted in wrong posterior")
+
+		// A bad verdict can't be wrong if there are no false bads
+		rep.updatePosterior(1, Bad)
+		assert.InDeltaSlice(t, []float64{0, 1, 0}, rep.posterior, 1e-9, "Noisy bad verdict resulted in wrong posterior")
+	})
+
+	t.Run("Impossible verdicts are ignored", func(t *testing.T) {
+		rep := newProbabilisticReplica(5, ProbabilisticConfig{})
+
+		rep.updatePosterior(1, Bad)
+		rep.updatePosterior(3, Good)
+		assert.InDeltaSlice(t, []float64{0, 1, 0, 0, 0}, rep.posterior, 1e-9, "Impossible verdict changed the posterior")
+	})
+}
+
+func TestGetMostInformativeCommit(t *testing.T) {
+	t.Run("Uniform posterior is halved", func(t *testing.T) {
+		rep := newProbabilisticReplica(7, ProbabilisticConfig{FalseGoodRate: 0.2})
+		assert.Equal(t, 3, rep.getMostInformativeCommit(), "Wrong commit chosen for uniform posterior")
+	})
+
+	t.Run("Skipped commits are avoided", func(t *testing.T) {
+		rep := newProbabilisticReplica(7, ProbabilisticConfig{FalseGoodRate: 0.2})
+		rep.skippedCommits[3] = true
+		assert.Contains(t, []int{2, 4}, rep.getMostInformativeCommit(), "Skipped commit chosen")
+
+		for i := 1; i < 6; i++ {
+			rep.skippedCommits[i] = true
+		}
+		assert.Equal(t, -1, rep.getMostInformativeCommit(), "Commit chosen even though all were skipped")
+	})
+
+	t.Run("Flaky good commit is retested", func(t *testing.T) {
+		rep := newProbabilisticReplica(4, ProbabilisticConfig{FalseGoodRate: 0.5})
+		rep.updatePosterior(2, Bad)
+		rep.updatePosterior(1, Good)
+
+		// Offset 3 is ruled out by the bad verdict, so only retesting the possibly flaky commit with offset 1 yields any information
+		assert.Equal(t, 1, rep.getMostInformativeCommit(), "Flaky commit not retested")
+	})
+}
+
+func TestGetMostLikelyCommit(t *testing.T) {
+	rep := newProbabilisticReplica(5, ProbabilisticConfig{FalseGoodRate: 0.1})
+	rep.updatePosterior(2, Bad)
+	rep.updatePosterior(1, Good)
+
+	commit, probability := rep.getMostLikelyCommit()
+	assert.Equal(t, 2, commit, "Wrong most likely commit")
+	assert.Greater(t, probability, 0.9, "Probability of most likely commit too low")
+}
+
+func TestProbabilisticConfigValidate(t *testing.T) {
+	config := ProbabilisticConfig{FalseGoodRate: 0.3}
+	assert.NoError(t, config.validate(), "Valid config raised an error")
+	assert.Equal(t, 0.95, config.Confidence, "Default confidence not set")
+
+	assert.Error(t, (&ProbabilisticConfig{FalseGoodRate: 1}).validate(), "Invalid false good rate didn't raise an error")
+	assert.Error(t, (&ProbabilisticConfig{FalseBadRate: -0.1}).validate(), "Invalid false bad rate didn't raise an error")
+	assert.Error(t, (&ProbabilisticConfig{Confidence: 1.5}).validate(), "Invalid confidence didn't raise an error")
+	assert.Error(t, (&ProbabilisticConfig{FalseGoodRate: 0.1, Confidence: 1}).validate(), "Unreachable confidence didn't raise an error")
+	assert.NoError(t, (&ProbabilisticConfig{Confidence: 1}).validate(), "Confidence of 1 without error rates raised an error")
+}
+
+func TestGetMostInformativeCommits(t *testing.T) {
+	rep := newProbabilisticReplica(9, ProbabilisticConfig{FalseGoodRate: 0.2})
+
+	commits := rep.getMostInformativeCommits(3)
+	assert.Len(t, commits, 3, "Wrong amount of commits returned")
+	assert.Equal(t, rep.getMostInformativeCommit(), commits[0], "Most informative commit not returned first")
+
+	assert.Len(t, rep.getMostInformativeCommits(100), 7, "More commits returned than untested ones exist")
+}
@@ -0,0 +1,284 @@
+package biscepter
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// buildRetryBackoff is how long to wait before retrying a build which failed transiently for the first time. It doubles with every retry
+var buildRetryBackoff = 5 * time.Second
+
+// buildLogExcerptLines is the amount of lines of the build log which are kept in the replacements backup
+const buildLogExcerptLines = 20
+
+// transientBuildErrors are parts of the messages of build errors which aren't caused by the built commit, e.g. network errors while pulling base images
+var transientBuildErrors = []string{
+	"i/o timeout",
+	"tls handshake timeout",
+	"connection reset",
+	"connection refused",
+	"no such host",
+	"temporary failure",
+	"unexpected eof",
+	"context deadline exceeded",
+	"client.timeout exceeded",
+	"toomanyrequests",
+	"too many requests",
+	"service unavailable",
+	"bad gateway",
+	"failed to resolve source metadata",
+	"error pulling image",
+	"failed to fetch",
+}
+
+// A buildFailure describes why building the image of a commit failed
+type buildFailure struct {
+	transient bool   // Whether the failure wasn't caused by the commit, e.g. a network error or a hiccup of the docker daemon, such that the build may be retried
+	reason    string // The error reported by docker
+	log       string // The last lines of the build log
+}
+
+func (f buildFailure) Error() string {
+	return f.reason
+}
+
+// buildMessage is a single message of the stream returned by the docker daemon while building an image
+type buildMessage struct {
+	Stream      string `json:"stream"`
+	Error       string `json:"error"`
+	ErrorDetail struct {
+		Message string `json:"message"`
+	} `json:"errorDetail"`
+}
+
+// parseBuildOutput parses the passed output of an image build and returns how the build failed, or nil if it succeeded.
+// Failures of build steps are deterministic, while errors which aren't caused by the commit, e.g. failing to pull the base image, are transient.
+func parseBuildOutput(out []byte) *buildFailure {
+	var log strings.Builder
+	var reason string
+	for _, line := range strings.Split(string(out), "\n") {
+		var msg buildMessage
+		if err := json.Unmarshal([]byte(line), &msg); err != nil {
+			continue
+		}
+		log.WriteString(msg.Stream)
+		if msg.ErrorDetail.Message != "" {
+			reason = msg.ErrorDetail.Message
+		} else if msg.Error != "" {
+			reason = msg.Error
+		}
+	}
+	if reason == "" {
+		return nil
+	}
+
+	return &buildFailure{
+		transient: isTransientBuildError(reason),
+		reason:    reason,
+		log:       getLogExcerpt(log.String() + reason),
+	}
+}
+
+// isTransientBuildError returns whether the passed build error message hints at a failure which isn't caused by the built commit.
+// A build step returning a non-zero code is always caused by the commit.
+func isTransientBuildError(message string) bool {
+	message = strings.ToLower(message)
+	if strings.Contains(message, "returned a non-zero code") || strings.Contains(message, "did not complete successfully") {
+		return false
+	}
+	for _, transientError := range transientBuildErrors {
+		if strings.Contains(message, transientError) {
+			return true
+		}
+	}
+	return false
+}
+
+// getLogExcerpt returns the last lines of the passed log
+func getLogExcerpt(log string) string {
+	lines := strings.Split(strings.TrimSpace(log), "\n")
+	return strings.Join(lines[max(len(lines)-buildLogExcerptLines, 0):], "\n")
+}
+
+// A FailureKind states how a commit which is replaced broke the build
+type FailureKind string
+
+const (
+	// The image of the commit failed to build, due to a failing build step
+	BuildFailed FailureKind = "build"
+	// The image of the commit was built, but its system failed the healthchecks
+	HealthcheckFailed FailureKind = "healthcheck"
+)
+
+// A CommitReplacement is an entry of the commit replacements store, stating which commit replaces a commit that broke the build, and why.
+// Entries are scoped to the repository and dockerfile they were recorded with, since a commit breaking the build with one dockerfile may build fine with another.
+type CommitReplacement struct {
+	Repository     string `json:"repository,omitempty"`     // The URL of the repository of the job which recorded the entry
+	DockerfileHash string `json:"dockerfileHash,omitempty"` // The hash of the dockerfile of the job which recorded the entry
+
+	Commit      string `json:"commit"`      // The commit which broke the build
+	Replacement string `json:"replacement"` // The commit which is tested instead of Commit
+
+	Kind      FailureKind `json:"kind,omitempty"`   // How the commit broke the build. Not set for entries written by older versions
+	Reason    string      `json:"reason,omitempty"` // Why the commit is broken, e.g. the error of the failed build step or healthcheck
+	Log       string      `json:"log,omitempty"`    // The last lines of the build log, if the build failed
+	Timestamp time.Time   `json:"timestamp"`        // When the entry was recorded
+}
+
+// IsScoped returns whether the entry belongs to a repository and dockerfile.
+// Entries written by older versions aren't scoped, and apply to every job.
+func (c CommitReplacement) IsScoped() bool {
+	return c.Repository != "" || c.DockerfileHash != ""
+}
+
+// AppliesTo returns whether the entry applies to jobs bisecting the passed repository using the dockerfile with the passed hash
+func (c CommitReplacement) AppliesTo(repository, dockerfileHash string) bool {
+	return !c.IsScoped() || (c.Repository == repository && c.DockerfileHash == dockerfileHash)
+}
+
+// DecodeCommitReplacements reads in the entries of a commit replacements store.
+// Every entry is a JSON object on its own line, but lines of comma-separated commit:replacement pairs written by older versions are read as well.
+func DecodeCommitReplacements(r io.Reader) ([]CommitReplacement, error) {
+	replacements, err := io.ReadAll(r)
+	if err != nil {
+		return nil, errors.Join(fmt.Errorf("couldn't read replacements"), err)
+	}
+
+	entries := []CommitReplacement{}
+	for _, line := range strings.Split(string(replacements), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		if strings.HasPrefix(line, "{") {
+			var entry CommitReplacement
+			if err := json.Unmarshal([]byte(line), &entry); err != nil {
+				return nil, errors.Join(fmt.Errorf("format of replacements file entry incorrect: %s", line), err)
+			}
+			if entry.Commit == "" || entry.Replacement == "" {
+				return nil, fmt.Errorf("replacements file entry is missing its commit or replacement: %s", line)
+			}
+			entries = append(entries, entry)
+			continue
+		}
+
+		for _, pair := range strings.Split(strings.TrimSuffix(line, ","), ",") {
+			split := strings.Split(pair, ":")
+			if len(split) != 2 {
+				return nil, fmt.Errorf("format of replacements file entry incorrect: %s", pair)
+			}
+			entries = append(entries, CommitReplacement{
+				Commit:      split[0],
+				Replacement: split[1],
+			})
+		}
+	}
+	return entries, nil
+}
+
+// EncodeCommitReplacements writes the passed entries in the format of a commit replacements store
+func EncodeCommitReplacements(w io.Writer, replacements []CommitReplacement) error {
+	for _, entry := range replacements {
+		entryBytes, err := json.Marshal(entry)
+		if err != nil {
+			return errors.Join(fmt.Errorf("couldn't encode replacement of commit %s", entry.Commit), err)
+		}
+		if _, err := w.Write(append(entryBytes, '\n')); err != nil {
+			return errors.Join(fmt.Errorf("couldn't write replacement of commit %s", entry.Commit), err)
+		}
+	}
+	return nil
+}
+
+// ReadCommitReplacements reads in the entries of the commit replacements store at the passed path, such as a job's CommitReplacementsBackup.
+// If the store doesn't exist, no entries are returned.
+func ReadCommitReplacements(path string) ([]CommitReplacement, error) {
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return []CommitReplacement{}, nil
+	} else if err != nil {
+		return nil, errors.Join(fmt.Errorf("couldn't open replacements %s", path), err)
+	}
+	defer file.Close()
+	return DecodeCommitReplacements(file)
+}
+
+// WriteCommitReplacements replaces the entries of the commit replacements store at the passed path with the passed entries.
+// The store is replaced atomically. It shouldn't be modified while a job using it is running, since the job keeps appending to the replaced store.
+func WriteCommitReplacements(path string, replacements []CommitReplacement) error {
+	var buf bytes.Buffer
+	if err := EncodeCommitReplacements(&buf, replacements); err != nil {
+		return err
+	}
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, buf.Bytes(), 0644); err != nil {
+		return errors.Join(fmt.Errorf("couldn't write replacements to %s", tmpPath), err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return errors.Join(fmt.Errorf("couldn't move replacements to %s", path), err)
+	}
+	return nil
+}
+
+// readReplacements opens the job's commit replacements store for appending and reads in the entries which apply to the job.
+// In startup and build mode, only entries of commits whose build failed deterministically are applied, since their verdicts depend on what kind of failure an entry stems from.
+// In build mode, these entries are the failed builds instead of being replaced.
+// The job's dockerfile has to be parsed beforehand.
+func (job *Job) readReplacements() error {
+	if job.CommitReplacementsBackup == "" {
+		job.CommitReplacementsBackup = ".biscepter-replacements~"
+	}
+	var err error
+	job.commitReplacementsBackupFile, err = os.OpenFile(job.CommitReplacementsBackup, os.O_APPEND|os.O_WRONLY|os.O_CREATE, 0644)
+	if err != nil {
+		return errors.Join(fmt.Errorf("couldn't get replacements backup"), err)
+	}
+	replacements, err := os.ReadFile(job.CommitReplacementsBackup)
+	if err != nil {
+		return errors.Join(fmt.Errorf("couldn't read replacements"), err)
+	}
+	// Backups written by older versions don't end with a newline
+	if len(replacements) != 0 && replacements[len(replacements)-1] != '\n' {
+		job.commitReplacementsBackupFile.WriteString("\n")
+	}
+
+	entries, err := DecodeCommitReplacements(bytes.NewReader(replacements))
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if !entry.AppliesTo(job.Repository, job.dockerfileHash) {
+			continue
+		}
+		if job.Mode != RunMode && entry.Kind != BuildFailed {
+			// Failed healthchecks are the verdicts of the commits in startup mode, and don't mean that the build failed in build mode.
+			// Entries of older versions may stem from either, or even from transient build failures
+			job.Log.Debugf("Ignoring replacement of commit %s from replacements file in %s mode, as it isn't known to break the build", entry.Commit, job.Mode)
+			continue
+		}
+		if job.Mode == BuildMode {
+			// The build failures are the verdicts of the commits, so they mustn't be replaced
+			job.Log.Debugf("Adding failed build from replacements file: %s", entry.Commit)
+			job.failedBuilds.Store(entry.Commit, true)
+			continue
+		}
+		job.Log.Debugf("Adding replacement from replacements file: %s -> %s", entry.Commit, entry.Replacement)
+		job.commitReplacements.Store(entry.Commit, entry.Replacement)
+	}
+	return nil
+}
+
+// writeReplacement scopes the passed entry to the job and appends it to the job's commit replacements store
+func (job *Job) writeReplacement(entry CommitReplacement) error {
+	entry.Repository = job.Repository
+	entry.DockerfileHash = job.dockerfileHash
+	entry.Timestamp = time.Now()
+	return EncodeCommitReplacements(job.commitReplacementsBackupFile, []CommitReplacement{entry})
+}
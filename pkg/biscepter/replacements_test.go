@@ -0,0 +1,182 @@
+package biscepter
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecodeCommitReplacements(t *testing.T) {
+	values := []struct {
+		name         string
+		replacements string
+
+		expected []CommitReplacement
+		err      bool
+	}{
+		{"Empty", "", []CommitReplacement{}, false},
+		{"Legacy", "a:b,c:d,", []CommitReplacement{{Commit: "a", Replacement: "b"}, {Commit: "c", Replacement: "d"}}, false},
+		{"Entries", `{"repository":"repo","dockerfileHash":"hash","commit":"a","replacement":"b","reason":"step failed","log":"line 1\nline 2","timestamp":"2024-05-01T12:00:00Z"}` + "\n",
+			[]CommitReplacement{{Repository: "repo", DockerfileHash: "hash", Commit: "a", Replacement: "b", Reason: "step failed", Log: "line 1\nline 2", Timestamp: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)}}, false},
+		{"Legacy followed by entries", "a:b,\n" + `{"commit":"c","replacement":"d","timestamp":"0001-01-01T00:00:00Z"}` + "\n",
+			[]CommitReplacement{{Commit: "a", Replacement: "b"}, {Commit: "c", Replacement: "d"}}, false},
+		{"Invalid legacy pair", "a:b:c,", nil, true},
+		{"Invalid entry", `{"commit":"a"`, nil, true},
+		{"Entry without replacement", `{"commit":"a"}`, nil, true},
+	}
+
+	for _, v := range values {
+		entries, err := DecodeCommitReplacements(strings.NewReader(v.replacements))
+		if v.err {
+			assert.Errorf(t, err, "%s: no error returned", v.name)
+		} else {
+			assert.NoErrorf(t, err, "%s: error returned", v.name)
+			assert.Equalf(t, v.expected, entries, "%s: wrong entries", v.name)
+		}
+	}
+}
+
+func TestCommitReplacementAppliesTo(t *testing.T) {
+	values := []struct {
+		entry CommitReplacement
+
+		expected bool
+	}{
+		{CommitReplacement{}, true},
+		{CommitReplacement{Repository: "repo", DockerfileHash: "hash"}, true},
+		{CommitReplacement{Repository: "other", DockerfileHash: "hash"}, false},
+		{CommitReplacement{Repository: "repo", DockerfileHash: "other"}, false},
+		{CommitReplacement{Repository: "repo"}, false},
+	}
+
+	for _, v := range values {
+		assert.Equalf(t, v.expected, v.entry.AppliesTo("repo", "hash"), "Wrong result for entry %+v", v.entry)
+	}
+}
+
+func TestReadAndWriteReplacements(t *testing.T) {
+	backup := filepath.Join(t.TempDir(), "replacements")
+	assert.NoError(t, os.WriteFile(backup, []byte("a:b,"), 0644), "Failed to write legacy replacements")
+
+	newJob := func(dockerfileHash string) *Job {
+		return &Job{
+			Log:                      logrus.StandardLogger(),
+			Repository:               "repo",
+			CommitReplacementsBackup: backup,
+			dockerfileHash:           dockerfileHash,
+			commitReplacements:       &sync.Map{},
+			failedBuilds:             &sync.Map{},
+		}
+	}
+
+	job := newJob("hash")
+	assert.NoError(t, job.readReplacements(), "Failed to read legacy replacements")
+	assert.NoError(t, job.writeReplacement(CommitReplacement{Commit: "c", Replacement: "d", Kind: BuildFailed, Reason: "step failed", Log: "log"}), "Failed to write replacement")
+	assert.NoError(t, job.writeReplacement(CommitReplacement{Commit: "e", Replacement: "f", Kind: HealthcheckFailed, Reason: "healthcheck failed"}), "Failed to write replacement")
+	job.commitReplacementsBackupFile.Close()
+
+	entries, err := ReadCommitReplacements(backup)
+	assert.NoError(t, err, "Failed to read replacements")
+	if assert.Len(t, entries, 3, "Wrong amount of replacements") {
+		assert.Equal(t, "repo", entries[1].Repository, "Replacement wasn't scoped to the repository")
+		assert.Equal(t, "hash", entries[1].DockerfileHash, "Replacement wasn't scoped to the dockerfile")
+		assert.False(t, entries[1].Timestamp.IsZero(), "Timestamp of replacement missing")
+	}
+
+	job = newJob("hash")
+	assert.NoError(t, job.readReplacements(), "Failed to read replacements")
+	job.commitReplacementsBackupFile.Close()
+	for commit, replacement := range map[string]string{"a": "b", "c": "d", "e": "f"} {
+		actual, ok := job.commitReplacements.Load(commit)
+		assert.Truef(t, ok, "Replacement of commit %s missing", commit)
+		assert.Equalf(t, replacement, actual, "Wrong replacement of commit %s", commit)
+	}
+
+	job = newJob("other")
+	assert.NoError(t, job.readReplacements(), "Failed to read replacements with another dockerfile")
+	job.commitReplacementsBackupFile.Close()
+	_, ok := job.commitReplacements.Load("a")
+	assert.True(t, ok, "Unscoped replacement not applied")
+	_, ok = job.commitReplacements.Load("c")
+	assert.False(t, ok, "Replacement of another dockerfile applied")
+
+	job = newJob("hash")
+	job.Mode = StartupMode
+	assert.NoError(t, job.readReplacements(), "Failed to read replacements in startup mode")
+	job.commitReplacementsBackupFile.Close()
+	_, ok = job.commitReplacements.Load("c")
+	assert.True(t, ok, "Commit breaking the build not replaced in startup mode")
+	for _, commit := range []string{"a", "e"} {
+		_, ok = job.commitReplacements.Load(commit)
+		assert.Falsef(t, ok, "Commit %s, which isn't known to break the build, replaced in startup mode", commit)
+	}
+
+	job = newJob("hash")
+	job.Mode = BuildMode
+	assert.NoError(t, job.readReplacements(), "Failed to read replacements in build mode")
+	job.commitReplacementsBackupFile.Close()
+	for _, commit := range []string{"a", "c", "e"} {
+		_, ok = job.commitReplacements.Load(commit)
+		assert.Falsef(t, ok, "Commit %s replaced in build mode", commit)
+	}
+	_, ok = job.failedBuilds.Load("c")
+	assert.True(t, ok, "Commit breaking the build not treated as failed build in build mode")
+	for _, commit := range []string{"a", "e"} {
+		_, ok = job.failedBuilds.Load(commit)
+		assert.Falsef(t, ok, "Commit %s, which isn't known to break the build, treated as failed build in build mode", commit)
+	}
+
+	assert.NoError(t, WriteCommitReplacements(backup, entries[1:]), "Failed to write replacements")
+	written, err := ReadCommitReplacements(backup)
+	assert.NoError(t, err, "Failed to read written replacements")
+	assert.Equal(t, entries[1:], written, "Written replacements differ")
+
+	missing, err := ReadCommitReplacements(filepath.Join(t.TempDir(), "missing"))
+	assert.NoError(t, err, "Reading missing replacements returned an error")
+	assert.Empty(t, missing, "Missing replacements aren't empty")
+}
+
+func TestParseBuildOutput(t *testing.T) {
+	values := []struct {
+		name string
+		out  string
+
+		failed    bool
+		transient bool
+	}{
+		{"Success", `{"stream":"Step 1/2 : FROM alpine\n"}` + "\n" + `{"stream":"Successfully built 1234\n"}`, false, false},
+		{"Failed step", `{"stream":"Step 2/2 : RUN false\n"}` + "\n" + `{"errorDetail":{"code":1,"message":"The command '/bin/sh -c false' returned a non-zero code: 1"},"error":"The command '/bin/sh -c false' returned a non-zero code: 1"}`, true, false},
+		{"Failed buildkit step", `{"errorDetail":{"message":"process \"/bin/sh -c go build\" did not complete successfully: exit code: 1"}}`, true, false},
+		{"Failed pull", `{"errorDetail":{"message":"Get \"https://registry-1.docker.io/v2/\": net/http: TLS handshake timeout"}}`, true, true},
+		{"Rate limited", `{"error":"toomanyrequests: You have reached your pull rate limit"}`, true, true},
+		{"Missing file", `{"errorDetail":{"message":"COPY failed: file not found in build context"}}`, true, false},
+	}
+
+	for _, v := range values {
+		failure := parseBuildOutput([]byte(v.out))
+		if !v.failed {
+			assert.Nilf(t, failure, "%s: build failure returned", v.name)
+			continue
+		}
+		if assert.NotNilf(t, failure, "%s: no build failure returned", v.name) {
+			assert.Equalf(t, v.transient, failure.transient, "%s: wrong classification", v.name)
+			assert.NotEmptyf(t, failure.reason, "%s: reason missing", v.name)
+		}
+	}
+}
+
+func TestGetLogExcerpt(t *testing.T) {
+	lines := []string{}
+	for i := range buildLogExcerptLines + 5 {
+		lines = append(lines, strings.Repeat("x", i))
+	}
+	excerpt := getLogExcerpt(strings.Join(lines, "\n") + "\n")
+	assert.Equal(t, strings.Join(lines[5:], "\n"), excerpt, "Wrong log excerpt")
+	assert.Equal(t, "short", getLogExcerpt("short"), "Wrong log excerpt of short log")
+}
@@ -1,6 +1,7 @@
 package biscepter
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
@@ -9,14 +10,18 @@ import (
 	"os"
 	"os/exec"
 	"path"
+	"slices"
+	"sort"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/dchest/uniuri"
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/client"
 	"github.com/docker/docker/pkg/archive"
+	"github.com/docker/docker/pkg/stdcopy"
 	"github.com/docker/go-connections/nat"
 	"github.com/otiai10/copy"
 	"github.com/phayes/freeport"
@@ -31,124 +36,451 @@ type replica struct {
 
 	id string // ID of this replica
 
-	repoPath string // The path to this replica's copy of the repo under test
+	repoPath             string      // The path to this replica's copy of the repo under test
+	repoCopies           []string    // The paths to all of this replica's copies and worktrees of the repo under test. Includes repoPath
+	repoPaths            chan string // Pool of the paths of the repo copies which are not currently used by a build, one for every system that can be built concurrently
+	speculativeRepoPaths chan string // Pool of the paths of the repo worktrees which are not currently used by a speculative build
 
-	goodCommitOffset int // The offset to the original bad commit of the newest good commit
-	badCommitOffset  int // The offset to the original bad commit of the oldest bad commit
+	goodCommitOffset int // The offset to the original bad commit of the newest good commit. If the job is reversed, this is the newest bad commit instead
+	badCommitOffset  int // The offset to the original bad commit of the oldest bad commit. If the job is reversed, this is the oldest good commit instead
 
-	commits []string // This replica's commits, where commits[0] is the good commit and commits[N-1] is the bad commit
+	commits []string // This replica's commits, where commits[0] is the old commit and commits[N-1] is the new commit
 
-	waitingCond *sync.Cond // Condition variable used by goroutine created in replica.start to wait until the current commit was reported to be good or bad
+	waitingCond *sync.Cond // Condition variable used by goroutine created in replica.start to wait until all systems of the current round were rated or cancelled. Its lock guards the replica's state
 
-	isStopped bool // Whether this replica is running
+	isStopped   bool // Whether this replica is running
+	isFinished  bool // Whether this replica found its offending commit
+	isCancelled bool // Whether this replica was cancelled using Job.CancelReplica. Such replicas aren't started again when the job is resumed
 
-	lastRunningSystem *RunningSystem // The last running system created by this replica. Is shut down when the replica is stopped
+	rsChan chan RunningSystem   // The channel to which this replica's systems are sent. Set once the replica is started
+	ocChan chan OffendingCommit // The channel to which this replica's offending commit is sent. Set once the replica is started
+
+	activeSystems  map[string]*RunningSystem // The running systems of this replica which were sent out and not yet rated or cancelled, keyed by their container name. Are shut down when the replica is stopped
+	pendingSystems int                       // The amount of systems of the current round which are starting up or were sent out and not yet rated or cancelled
 
 	log *logrus.Entry
 
 	possibleOtherCommits []string
+
+	skippedCommits map[int]bool // Offsets of the commits which were reported as untestable for this replica
+
+	verdicts []VerdictRecord // Every verdict this replica received, in order
+
+	posterior []float64 // The probability of each commit being the offending commit. Only set if the job bisects probabilistically
+
+	candidates commitSet // The commits which could still be the offending commit, including the current bad commit. Only set if the job bisects the full history
+
+	mergePath     []MergeStep       // The merge commits this replica descended into, in order
+	octopusMerges map[string]string // The octopus commits which the commits created by createOctopusChain were split off from
+
+	history []replicaCheckpoint // The bisection state before each applied verdict, oldest first. Used to undo verdicts
+
+	inconsistencies []string // Descriptions of the inconsistent verdicts this replica received
+
+	offendingCommit      *OffendingCommit  // The offending commit which is being verified, if any
+	verificationVerdicts map[int][]Verdict // The normalized verdicts of the re-tests of the commits verifying offendingCommit, by commit offset
+	restarts             int               // How many times the bisection was restarted because verifying the offending commit failed
+
+	startupFailures map[string]startupFailure // Why the commits which failed to start up did so, by commit hash. Only set in startup mode
+}
+
+// A commitWindow is a snapshot of the commits a replica's bisection is restricted to at some point in time.
+// Commits breaking the build are replaced by other commits of the window, since the replica's state may change while systems are started.
+type commitWindow struct {
+	commits []string // The replica's commits
+
+	goodCommitOffset int // The offset of the replica's good commit
+	badCommitOffset  int // The offset of the replica's bad commit
+
+	candidates commitSet // The replica's candidates. Only set if the job bisects the full history
 }
 
 func createJobReplica(j *Job, index int, id string) (*replica, error) {
-	// Copy the repo
-	dir, err := os.MkdirTemp("", "biscepter")
-	if err != nil {
-		return nil, err
+	// Copy the repo, once for every system that can be built concurrently
+	repoCopies := make([]string, max(j.Parallelism, 1))
+	repoPaths := make(chan string, len(repoCopies))
+	for i := range repoCopies {
+		dir, err := os.MkdirTemp("", "biscepter")
+		if err != nil {
+			return nil, err
+		}
+		if err := copy.Copy(j.repoPath, dir, copy.Options{
+			Specials:     true,
+			NumOfWorkers: int64(j.MaxConcurrentReplicas),
+		}); err != nil {
+			return nil, err
+		}
+		repoCopies[i] = dir
+		repoPaths <- dir
 	}
-	if err := copy.Copy(j.repoPath, dir, copy.Options{
-		Specials:     true,
-		NumOfWorkers: int64(j.MaxConcurrentReplicas),
-	}); err != nil {
-		return nil, err
+
+	// Create worktrees for speculatively building both possible next commits of every system
+	var speculativeRepoPaths chan string
+	if j.SpeculativeBuilds {
+		speculativeRepoPaths = make(chan string, 2*len(repoCopies))
+		for range cap(speculativeRepoPaths) {
+			dir, err := os.MkdirTemp("", "biscepter")
+			if err != nil {
+				return nil, err
+			}
+			cmd := exec.Command("git", "worktree", "add", "--detach", dir)
+			cmd.Dir = repoCopies[0]
+			if out, err := cmd.CombinedOutput(); err != nil {
+				return nil, errors.Join(fmt.Errorf("git worktree creation at %s failed for replica %d, output: %s", dir, index, out), err)
+			}
+			repoCopies = append(repoCopies, dir)
+			speculativeRepoPaths <- dir
+		}
 	}
 
-	return &replica{
+	rep := &replica{
 		parentJob: j,
 
 		index: index,
 		id:    id,
 
-		repoPath: dir,
+		repoPath:             repoCopies[0],
+		repoCopies:           repoCopies,
+		repoPaths:            repoPaths,
+		speculativeRepoPaths: speculativeRepoPaths,
 
 		goodCommitOffset: 0,
 		badCommitOffset:  len(j.commits) - 1,
 
 		commits: j.commits,
 
+		activeSystems: make(map[string]*RunningSystem),
+
+		skippedCommits: make(map[int]bool),
+
 		waitingCond: sync.NewCond(&sync.Mutex{}),
 
 		log: j.Log.WithField("replica-id", id),
-	}, nil
+	}
+
+	if j.Probabilistic != nil {
+		rep.initPosterior()
+	}
+	if j.FullHistory {
+		rep.initCandidates()
+	}
+
+	return rep, nil
 }
 
 func (r *replica) start(rsChan chan RunningSystem, ocChan chan OffendingCommit) error {
+	// Kept for restarting the bisection once a verdict was undone
+	r.rsChan, r.ocChan = rsChan, ocChan
+
 	// Create goroutine for the replica
 	go func() {
+		r.waitingCond.L.Lock()
 		for !r.isStopped {
 			// Check if offending commit was found, terminate if yes
 			if oc := r.getOffendingCommit(); oc != nil {
+				r.isFinished = true
+				r.waitingCond.L.Unlock()
 				ocChan <- *oc
-				break
+				return
 			}
-			r.waitingCond.L.Lock()
 
-			readySystem, err := r.initNextSystem()
-			if err != nil {
-				// TODO: What to do here?
-				r.log.Panicf("Replica %d failed to init next system - %v", r.index, err)
+			// Start the next round of systems
+			window := r.getWindow()
+			for _, commitOffset := range r.getNextCommits(r.parentJob.Parallelism) {
+				r.pendingSystems++
+				go r.launchSystem(window, commitOffset, rsChan)
 			}
-			rsChan <- *readySystem
 
-			// Wait until commit was reported to be good or bad
-			r.waitingCond.Wait()
-			r.waitingCond.L.Unlock()
+			// Wait until all systems of this round were rated or cancelled
+			for r.pendingSystems > 0 && !r.isStopped {
+				r.waitingCond.Wait()
+			}
 		}
+		r.waitingCond.L.Unlock()
 	}()
 
 	return nil
 }
 
+// cancel marks this replica as stopped before it found its offending commit, such that it won't send out any more systems or an offending commit.
+// The replica has to be stopped using stop afterwards.
+// An error is returned if the replica already found its offending commit or was stopped before.
+func (r *replica) cancel() error {
+	r.waitingCond.L.Lock()
+	defer r.waitingCond.L.Unlock()
+
+	if r.isFinished || r.isStopped {
+		return fmt.Errorf("replica %d already finished its bisection or was stopped", r.index)
+	}
+	r.isStopped = true
+	r.isCancelled = true
+	r.saveCheckpoint()
+	return nil
+}
+
 func (r *replica) stop() error {
+	r.waitingCond.L.Lock()
+	defer r.waitingCond.L.Unlock()
+
 	// Stop goroutine
 	r.isStopped = true
 	r.waitingCond.Signal()
 
-	if r.lastRunningSystem != nil {
-		r.lastRunningSystem.stop()
+	for _, rs := range r.activeSystems {
+		r.parentJob.replicaSemaphore.Release(1)
+		rs.stop()
 	}
+	r.activeSystems = make(map[string]*RunningSystem)
 
-	// Clean up tmp directory of repo
-	return os.RemoveAll(r.repoPath)
+	// Clean up tmp directories of repo
+	for _, repoPath := range r.repoCopies {
+		if err := os.RemoveAll(repoPath); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
-func (r *replica) isGood(rs RunningSystem) {
-	if rs.commitRootOffset < r.goodCommitOffset {
+// rate applies the passed verdict of the passed running system to this replica.
+// Systems which became irrelevant due to the verdict are stopped.
+func (r *replica) rate(rs RunningSystem, verdict Verdict) {
+	r.waitingCond.L.Lock()
+	defer r.waitingCond.L.Unlock()
+
+	if _, ok := r.activeSystems[rs.containerName]; !ok {
+		r.recordVerdict(rs, verdict)
+		r.log.Infof("Ignoring verdict %s of commit %s, as its system was already stopped", verdict, rs.Commit)
+		if r.offendingCommit == nil {
+			r.checkConsistency(rs.commitRootOffset, r.parentJob.normalizeVerdict(verdict))
+		}
 		return
 	}
-	r.goodCommitOffset = rs.commitRootOffset
 
-	// Release the in initNextSystem acquired semaphore with a weight of 1
-	r.parentJob.replicaSemaphore.Release(1)
+	r.pushHistory()
+	r.recordVerdict(rs, verdict)
+	if r.offendingCommit != nil {
+		r.addVerificationVerdict(rs.commitRootOffset, r.parentJob.normalizeVerdict(verdict))
+	} else {
+		r.applyVerdict(rs.commitRootOffset, r.parentJob.normalizeVerdict(verdict))
+	}
 
-	go func() {
-		if err := rs.stop(); err != nil {
-			r.log.Warnf("Failed to stop container %s - %v", rs.containerName, err)
+	r.releaseSystem(rs)
+	r.cancelIrrelevantSystems()
+
+	r.saveCheckpoint()
+}
+
+// applyVerdict narrows down the offending commit of this replica using the passed verdict for the commit with the passed offset.
+// The verdict has to be normalized, i.e. good stands for the behaviour of the old commit and bad for the behaviour of the new commit.
+func (r *replica) applyVerdict(commitOffset int, verdict Verdict) {
+	switch verdict {
+	case Good, Bad:
+		if r.candidates != nil {
+			r.applyGraphVerdict(commitOffset, verdict)
+			return
 		}
-	}()
+	}
 
-	// Signal goroutine started in start() to wake up again
+	switch verdict {
+	case Good:
+		if r.posterior != nil {
+			r.updatePosterior(commitOffset, Good)
+		} else if commitOffset > r.goodCommitOffset {
+			r.goodCommitOffset = commitOffset
+		}
+	case Bad:
+		if r.posterior != nil {
+			r.updatePosterior(commitOffset, Bad)
+		} else if commitOffset < r.badCommitOffset {
+			r.badCommitOffset = commitOffset
+		}
+	case Skip:
+		r.skippedCommits[commitOffset] = true
+	}
+}
+
+// recordVerdict appends the passed verdict for the passed running system to this replica's verdicts
+func (r *replica) recordVerdict(rs RunningSystem, verdict Verdict) {
+	r.verdicts = append(r.verdicts, VerdictRecord{
+		Commit:    rs.Commit,
+		Verdict:   verdict,
+		Output:    rs.verdictOutput,
+		Timestamp: time.Now(),
+		Notes:     rs.Notes,
+	})
+}
+
+// seedVerdicts narrows down this replica's commits using the passed verdicts, which were received before the bisection started.
+// Verdicts which can't be applied are ignored.
+func (r *replica) seedVerdicts(verdicts []VerdictRecord) {
+	for _, verdict := range verdicts {
+		if err := r.applyKnownVerdict(verdict); err != nil {
+			r.log.Warnf("Ignoring known verdict %s of commit %s - %v", verdict.Verdict, verdict.Commit, err)
+		}
+	}
+}
+
+// mark narrows down this replica's commits using the passed verdict of the passed commit, which wasn't received via a running system.
+// Running systems whose commits became irrelevant are stopped, and replaced by the next round of systems once none of the current round are left.
+func (r *replica) mark(verdict VerdictRecord) error {
 	r.waitingCond.L.Lock()
-	r.waitingCond.Signal()
-	r.waitingCond.L.Unlock()
+	defer r.waitingCond.L.Unlock()
+
+	if r.isFinished || r.isStopped {
+		return fmt.Errorf("replica %d already finished its bisection or was stopped", r.index)
+	}
+	if err := r.applyKnownVerdict(verdict); err != nil {
+		return err
+	}
+
+	r.cancelIrrelevantSystems()
+	r.saveCheckpoint()
+	return nil
 }
 
-func (r *replica) isBad(rs RunningSystem) {
-	if rs.commitRootOffset > r.badCommitOffset {
-		return
+// pushHistory appends this replica's current bisection state to its history, so that the next applied verdict can be undone.
+// The lock of waitingCond has to be held when calling this method.
+func (r *replica) pushHistory() {
+	r.history = append(r.history, r.getCheckpoint())
+}
+
+// undo rolls this replica's bisection state back to before the passed amount of its last applied verdicts, dropping their records.
+// All running systems are stopped, such that the next round of systems offers the commit of the oldest undone verdict again.
+// If the replica already found its offending commit, its bisection is started again, resulting in another offending commit being sent once it finished.
+func (r *replica) undo(steps int) error {
+	r.waitingCond.L.Lock()
+	defer r.waitingCond.L.Unlock()
+
+	if r.isStopped {
+		return fmt.Errorf("replica %d was stopped", r.index)
+	}
+	if steps < 1 || steps > len(r.history) {
+		return fmt.Errorf("can't undo %d verdicts, replica %d has %d verdicts which can be undone", steps, r.index, len(r.history))
+	}
+
+	state := r.history[len(r.history)-steps]
+	r.history = r.history[:len(r.history)-steps]
+	r.setState(state)
+	r.log.Infof("Undid %d verdicts, remaining window from commit %d to %d", steps, r.goodCommitOffset, r.badCommitOffset)
+
+	for _, rs := range r.activeSystems {
+		r.releaseSystem(*rs)
+	}
+	r.saveCheckpoint()
+
+	if r.isFinished {
+		r.isFinished = false
+		return r.start(r.rsChan, r.ocChan)
+	}
+	return nil
+}
+
+// applyKnownVerdict applies and records the passed verdict of a commit for which no system was started.
+// Verdicts of commits which aren't among this replica's commits are mapped to one of them using mapKnownVerdict.
+// An error is returned if the commit doesn't exist, can't be mapped, or the verdict contradicts the current window of commits.
+func (r *replica) applyKnownVerdict(verdict VerdictRecord) error {
+	commit, err := resolveCommit(verdict.Commit, r.repoPath)
+	if err != nil {
+		return err
+	}
+	normalizedVerdict := r.parentJob.normalizeVerdict(verdict.Verdict)
+	commitOffset := slices.Index(r.commits, commit)
+	if commitOffset == -1 {
+		commitOffset, err = r.mapKnownVerdict(commit, normalizedVerdict)
+		if err != nil {
+			return err
+		} else if commitOffset == -1 {
+			return fmt.Errorf("commit %s is not bisected by this replica", commit)
+		}
+		r.log.Infof("Mapped verdict %s of commit %s to commit %s", verdict.Verdict, commit, r.commits[commitOffset])
+		commit = r.commits[commitOffset]
+	}
+
+	if r.offendingCommit != nil {
+		return fmt.Errorf("replica %d is verifying its offending commit", r.index)
+	}
+	if !r.checkConsistency(commitOffset, normalizedVerdict) {
+		return fmt.Errorf("verdict %s of commit %s is inconsistent with the previous verdicts", verdict.Verdict, commit)
+	}
+
+	r.log.Infof("Applying verdict %s of commit %s", verdict.Verdict, commit)
+	r.pushHistory()
+	r.applyVerdict(commitOffset, normalizedVerdict)
+	verdict.Commit = commit
+	r.verdicts = append(r.verdicts, verdict)
+	return nil
+}
+
+// mapKnownVerdict returns the offset of the commit to which the passed normalized verdict of a commit which isn't among this replica's commits applies as well,
+// or -1 if there is no such commit.
+// A good commit is mapped to its nearest ancestor among the commits, as all of its ancestors are good as well.
+// A bad commit is mapped to the oldest of the commits it is an ancestor of, e.g. the merge commit which merged it, as all of its descendants are bad as well.
+// Skipped commits and commits of replicas bisecting the full history are never mapped.
+func (r replica) mapKnownVerdict(commit string, verdict Verdict) (int, error) {
+	if r.candidates != nil || (verdict != Good && verdict != Bad) {
+		return -1, nil
+	}
+
+	// Since the commits are first-parent ancestors of one another, whether a commit is an ancestor of the passed commit (or vice versa) only changes once.
+	// For good commits, low is always an ancestor and high never is; for bad commits, high is always a descendant and low never is.
+	isMatch := func(commitOffset int) (bool, error) {
+		if verdict == Good {
+			return isAncestor(r.commits[commitOffset], commit, r.repoPath)
+		}
+		return isAncestor(commit, r.commits[commitOffset], r.repoPath)
+	}
+	low, high := -1, len(r.commits)
+	for high-low > 1 {
+		mid := (low + high) / 2
+		match, err := isMatch(mid)
+		if err != nil {
+			return -1, err
+		}
+		if match == (verdict == Good) {
+			low = mid
+		} else {
+			high = mid
+		}
+	}
+
+	if verdict == Good {
+		return low, nil
+	} else if high == len(r.commits) {
+		return -1, nil
+	}
+	return high, nil
+}
+
+// isRelevant returns whether a verdict for the commit with the passed offset could still narrow down the offending commit
+func (r replica) isRelevant(commitOffset int) bool {
+	if r.offendingCommit != nil {
+		_, ok := r.verificationVerdicts[commitOffset]
+		return ok
+	}
+	if r.candidates != nil {
+		return r.candidates.has(commitOffset) && commitOffset != r.badCommitOffset
+	}
+	return r.posterior != nil || (commitOffset > r.goodCommitOffset && commitOffset < r.badCommitOffset)
+}
+
+// cancelIrrelevantSystems stops all active systems whose commits are no longer relevant for the bisection.
+// The lock of waitingCond has to be held when calling this method.
+func (r *replica) cancelIrrelevantSystems() {
+	for _, rs := range r.activeSystems {
+		if !r.isRelevant(rs.commitRootOffset) {
+			r.log.Infof("Commit %s is no longer relevant, stopping its system", rs.Commit)
+			r.releaseSystem(*rs)
+		}
 	}
-	r.badCommitOffset = rs.commitRootOffset
+}
+
+// releaseSystem stops the passed running system after it was rated or cancelled and wakes up the goroutine started in start().
+// The lock of waitingCond has to be held when calling this method.
+func (r *replica) releaseSystem(rs RunningSystem) {
+	delete(r.activeSystems, rs.containerName)
+	r.pendingSystems--
 
-	// Release the in initNextSystem acquired semaphore with a weight of 1
+	// Release the in initSystem acquired semaphore with a weight of 1
 	r.parentJob.replicaSemaphore.Release(1)
 
 	go func() {
@@ -158,31 +490,112 @@ func (r *replica) isBad(rs RunningSystem) {
 	}()
 
 	// Signal goroutine started in start() to wake up again
-	r.waitingCond.L.Lock()
 	r.waitingCond.Signal()
+}
+
+// launchSystem starts a system running the commit with the passed offset in the passed window and sends it out to be tested, unless it became irrelevant in the meantime
+func (r *replica) launchSystem(window commitWindow, commitOffset int, rsChan chan RunningSystem) {
+	var rs *RunningSystem
+	var err error
+	if r.parentJob.Mode == BuildMode {
+		rs, err = r.initBuild(window, commitOffset)
+	} else {
+		rs, err = r.initSystem(window, commitOffset)
+	}
+	if err != nil {
+		r.waitingCond.L.Lock()
+		isStopped := r.isStopped
+		r.waitingCond.L.Unlock()
+		if isStopped {
+			// The replica's repo copies may have been removed while building
+			r.log.Infof("Replica %d was stopped while starting a system, ignoring error - %v", r.index, err)
+			r.parentJob.replicaSemaphore.Release(1)
+			return
+		}
+		// The commit is skipped instead of aborting the whole job, no matter why its system couldn't be started
+		commitHash, reason := getActualCommit(window.commits[commitOffset], r.parentJob.commitReplacements), fmt.Sprintf("failed to start system - %v", err)
+		var skippedErr skippedCommitError
+		if errors.As(err, &skippedErr) {
+			commitHash, reason = skippedErr.commitHash, skippedErr.reason
+		} else {
+			r.log.Errorf("Replica %d failed to start the system of commit %s - %v", r.index, commitHash, err)
+		}
+		rs = &RunningSystem{
+			ReplicaIndex: r.index,
+
+			Commit: commitHash,
+
+			parentReplica: r,
+
+			containerName: "biscepter-skipped-" + uniuri.New(),
+
+			commitRootOffset: commitOffset,
+
+			verdictOutput: reason,
+
+			skipped: true,
+		}
+	}
+
+	r.waitingCond.L.Lock()
+	if r.isStopped || !r.isRelevant(commitOffset) {
+		r.log.Infof("Commit %s became irrelevant while starting its system, stopping it", rs.Commit)
+		r.releaseSystem(*rs)
+		r.waitingCond.L.Unlock()
+		return
+	}
+	r.activeSystems[rs.containerName] = rs
+	if rs.startupFailure != nil {
+		if r.startupFailures == nil {
+			r.startupFailures = make(map[string]startupFailure)
+		}
+		r.startupFailures[rs.Commit] = *rs.startupFailure
+	}
+	var speculativeCommits []int
+	if r.parentJob.SpeculativeBuilds && r.offendingCommit == nil {
+		speculativeCommits = r.getSpeculativeCommits(commitOffset)
+	}
+	currentWindow := r.getWindow()
 	r.waitingCond.L.Unlock()
+
+	for _, speculativeCommit := range speculativeCommits {
+		go r.buildSpeculatively(currentWindow, speculativeCommit)
+	}
+
+	if rs.skipped {
+		r.log.Warnf("Skipping commit %s - %s", rs.Commit, rs.verdictOutput)
+		rs.Rate(Skip)
+	} else if r.parentJob.Mode != RunMode {
+		// The outcome of the build or startup is the verdict
+		verdict := Good
+		if rs.buildFailed || rs.startupFailure != nil {
+			verdict = Bad
+		}
+		r.log.Infof("%s mode rated commit %s as %s", r.parentJob.Mode, rs.Commit, verdict)
+		rs.Rate(verdict)
+	} else if r.parentJob.VerdictCommand != "" {
+		// Rate the system using the verdict command instead of sending it out to be tested
+		r.rateByVerdictCommand(*rs)
+	} else {
+		rsChan <- *rs
+	}
 }
 
-func (r *replica) initNextSystem() (*RunningSystem, error) {
+// initSystem starts a system running the commit with the passed offset in the passed window and blocks until it passed all healthchecks.
+// If the commit breaks the build or fails the healthchecks, it is replaced by another commit of the window and the replacement is started instead.
+func (r *replica) initSystem(window commitWindow, commitOffset int) (*RunningSystem, error) {
 	// Acquire the semaphore with a weight of 1
 	r.parentJob.replicaSemaphore.Acquire(context.Background(), 1)
 
-	nextCommit := r.getNextCommit()
-	commitHash := getActualCommit(r.commits[nextCommit], r.parentJob.commitReplacements)
-
-	// Checkout new commit
-	cmd := exec.Command("sh", "-c", fmt.Sprintf("git add . && git reset --hard %s", commitHash))
-	cmd.Dir = r.repoPath
-	if out, err := cmd.CombinedOutput(); err != nil {
-		return nil, errors.Join(fmt.Errorf("git checkout of hash %s at %s failed for replica %d, output: %s", commitHash, r.repoPath, r.index, out), err)
+	commitHash, ok, err := r.buildImage(window, commitOffset, r.repoPaths)
+	if err != nil {
+		return nil, err
 	}
-
-	// Update all submodules
-	cmd = exec.Command("git", "submodule", "update", "--init", "--recursive")
-	cmd.Dir = r.repoPath
-	if out, err := cmd.CombinedOutput(); err != nil {
-		return nil, errors.Join(fmt.Errorf("git submodule update at %s failed for replica %d, output: %s", r.repoPath, r.index, out), err)
+	if !ok {
+		r.parentJob.replicaSemaphore.Release(1)
+		return r.initSystem(window, commitOffset)
 	}
+	imageName := r.parentJob.getDockerImageOfCommit(commitHash)
 
 	// Create docker client
 	apiClient, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
@@ -191,68 +604,6 @@ func (r *replica) initNextSystem() (*RunningSystem, error) {
 	}
 	defer apiClient.Close()
 
-	// Build the new image if it doesn't exist yet
-	imageName := r.parentJob.getDockerImageOfCommit(commitHash)
-	newLock := &sync.Mutex{}
-	l, _ := r.parentJob.imagesBuilding.LoadOrStore(commitHash, newLock)
-	lock := l.(*sync.Mutex)
-	lock.Lock()
-	if !r.parentJob.builtImages[imageName] {
-		r.log.Infof("Building image %s of commit %s", imageName, commitHash)
-		// Image has not been built yet
-		// TODO: Have to ensure there is no dockerfile being overwritten in dest repo
-		os.WriteFile(path.Join(r.repoPath, "Dockerfile"), []byte(r.parentJob.dockerfileString), 0777)
-		ctx, err := archive.TarWithOptions(r.repoPath, &archive.TarOptions{})
-		if err != nil {
-			return nil, errors.Join(fmt.Errorf("tar creation of dockerfile for commit hash %s failed for replica %d", commitHash, r.index), err)
-		}
-		buildRes, err := apiClient.ImageBuild(context.Background(), ctx, types.ImageBuildOptions{
-			Tags:        []string{imageName},
-			ForceRemove: true,
-			Labels:      map[string]string{"biscepter": "1"},
-		})
-		if err != nil {
-			out, _ := io.ReadAll(buildRes.Body)
-			logrus.Warnf("Image build of %s for commit hash %s failed, avoiding commit from now on. Build output: %s", imageName, commitHash, out)
-			r.parentJob.builtImages[imageName] = true
-			r.replaceCommit(nextCommit)
-			lock.Unlock()
-			r.parentJob.replicaSemaphore.Release(1)
-			return r.initNextSystem()
-		}
-		// Wait for build to be done
-		out, err := io.ReadAll(buildRes.Body)
-		if err != nil {
-			return nil, err
-		}
-		logrus.Tracef("Image build output:\n%s", string(out))
-
-		// Check if last stream message is an error-detail, meaning the build failed
-		strOut := strings.Split(string(out[:len(out)-1]), "\n")
-		if strings.HasPrefix(strOut[len(strOut)-1], `{"errorDetail"`) {
-			r.log.Warnf("Image build of %s for commit hash %s failed, avoiding commit from now on. Build output: %s", imageName, commitHash, out)
-			r.replaceCommit(nextCommit)
-			// Set to true s.t. waiting replicas don't attempt to rebuild
-			r.parentJob.builtImages[imageName] = true
-			lock.Unlock()
-			r.parentJob.replicaSemaphore.Release(1)
-			return r.initNextSystem()
-		}
-		r.parentJob.builtImages[imageName] = true
-		lock.Unlock()
-	} else {
-		if _, ok := r.parentJob.commitReplacements.Load(commitHash); ok {
-			// Commit breaks the build, init another system
-			r.log.Warnf("Image for commit hash %s reported to be broken, reattempting to init next system.", commitHash)
-			lock.Unlock()
-			r.parentJob.replicaSemaphore.Release(1)
-			return r.initNextSystem()
-		}
-		// Image has been built - reuse it
-		r.log.Infof("Image %s of commit %s already built, reusing image", imageName, commitHash)
-		lock.Unlock()
-	}
-
 	// Setup the ports
 	ports := make(map[int]int)
 	exposedPorts := make(nat.PortSet)
@@ -287,9 +638,9 @@ func (r *replica) initNextSystem() (*RunningSystem, error) {
 		Labels:       map[string]string{"biscepter": "1"},
 	}
 
-	// Setup the host config
+	// Setup the host config. In startup mode, the container is removed manually, so that its logs can be fetched if it exited
 	hostConfig := &container.HostConfig{
-		AutoRemove:   true,
+		AutoRemove:   r.parentJob.Mode != StartupMode,
 		PortBindings: portBindings,
 	}
 
@@ -310,53 +661,463 @@ func (r *replica) initNextSystem() (*RunningSystem, error) {
 
 	r.log.Infof("Started container %s running commit %s, performing healthchecks...", containerName, commitHash)
 
+	rs := &RunningSystem{
+		ReplicaIndex: r.index,
+
+		Ports: ports,
+
+		Commit: commitHash,
+
+		parentReplica: r,
+
+		containerName: containerName,
+
+		commitRootOffset: commitOffset,
+	}
+
 	// Perform healthchecks
 	for _, healthcheck := range r.parentJob.Healthchecks {
 		success, err := healthcheck.performHealthcheck(ports, r.log)
-		if !success {
-			r.replaceCommit(nextCommit)
-			logrus.Warnf("healthcheck on port %d failed for replica %d, treating commit %s as broken", healthcheck.Port, r.index, r.commits[nextCommit])
-			return r.initNextSystem()
+		if !success && r.parentJob.Mode == StartupMode {
+			rs.startupFailure = &startupFailure{
+				healthcheckError: fmt.Sprintf("healthcheck on port %d failed - %v", healthcheck.Port, err),
+			}
+			break
+		} else if !success {
+			replaceErr := r.replaceCommit(window, commitOffset, commitHash, HealthcheckFailed, fmt.Sprintf("healthcheck on port %d failed - %v", healthcheck.Port, err), "")
+			logrus.Warnf("healthcheck on port %d failed for replica %d, treating commit %s as broken", healthcheck.Port, r.index, commitHash)
+			if err := apiClient.ContainerStop(context.Background(), containerName, container.StopOptions{}); err != nil {
+				r.log.Warnf("Failed to stop container %s - %v", containerName, err)
+			}
+			if replaceErr != nil {
+				// The semaphore is released once the skipped system is rated
+				return nil, replaceErr
+			}
+			r.parentJob.replicaSemaphore.Release(1)
+			return r.initSystem(window, commitOffset)
 		} else if err != nil {
+			if err := apiClient.ContainerStop(context.Background(), containerName, container.StopOptions{}); err != nil {
+				r.log.Warnf("Failed to stop container %s - %v", containerName, err)
+			}
 			return nil, err
 		}
 	}
 
+	if r.parentJob.Mode == StartupMode {
+		r.finishStartup(apiClient, rs)
+		return rs, nil
+	}
+
 	r.log.Infof("Successfully performed healthchecks on container %s running commit %s", containerName, commitHash)
 
-	rs := &RunningSystem{
+	return rs, nil
+}
+
+// finishStartup removes the container of the passed system after its healthchecks were performed in startup mode, as the outcome of its startup is its verdict.
+// If the startup failed, the container's logs are fetched beforehand.
+func (r *replica) finishStartup(apiClient *client.Client, rs *RunningSystem) {
+	if rs.startupFailure == nil {
+		r.log.Infof("Commit %s started up successfully", rs.Commit)
+	} else {
+		r.log.Warnf("Commit %s failed to start up - %s", rs.Commit, rs.startupFailure.healthcheckError)
+		logs, err := getContainerLogs(apiClient, rs.containerName)
+		if err != nil {
+			r.log.Warnf("Failed to get logs of container %s - %v", rs.containerName, err)
+		}
+		rs.startupFailure.containerLogs = logs
+		rs.verdictOutput = rs.startupFailure.healthcheckError
+	}
+
+	if err := apiClient.ContainerRemove(context.Background(), rs.containerName, container.RemoveOptions{Force: true}); err != nil {
+		r.log.Warnf("Failed to remove container %s - %v", rs.containerName, err)
+	}
+}
+
+// getContainerLogs returns the last lines of the combined stdout and stderr of the container with the passed name
+func getContainerLogs(apiClient *client.Client, containerName string) (string, error) {
+	logs, err := apiClient.ContainerLogs(context.Background(), containerName, container.LogsOptions{
+		ShowStdout: true,
+		ShowStderr: true,
+		Tail:       "200",
+	})
+	if err != nil {
+		return "", err
+	}
+	defer logs.Close()
+
+	out := new(bytes.Buffer)
+	if _, err := stdcopy.StdCopy(out, out, logs); err != nil {
+		return out.String(), err
+	}
+	return out.String(), nil
+}
+
+// initBuild builds the image of the commit with the passed offset in the passed window without starting a container, for jobs in build mode.
+// The returned system can't be tested, but whether its build failed determines its verdict.
+func (r *replica) initBuild(window commitWindow, commitOffset int) (*RunningSystem, error) {
+	// Acquire the semaphore with a weight of 1
+	r.parentJob.replicaSemaphore.Acquire(context.Background(), 1)
+
+	commitHash, ok, err := r.buildImage(window, commitOffset, r.repoPaths)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RunningSystem{
 		ReplicaIndex: r.index,
 
-		Ports: ports,
+		Commit: commitHash,
 
 		parentReplica: r,
 
-		containerName: containerName,
+		containerName: "biscepter-build-" + uniuri.New(),
 
-		commit:           commitHash,
-		commitRootOffset: nextCommit,
+		commitRootOffset: commitOffset,
+
+		buildFailed: !ok,
+	}, nil
+}
+
+// getSpeculativeCommits returns the offsets of the commits which would be tested next if the commit with the passed offset was rated good or bad, and whose images were not built yet.
+// The lock of waitingCond has to be held when calling this method.
+func (r *replica) getSpeculativeCommits(commitOffset int) []int {
+	// Mute the logs of the hypothetical replicas
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	commitOffsets := []int{}
+	for _, verdict := range []Verdict{Good, Bad} {
+		hypothetical := replica{
+			parentJob: r.parentJob,
+
+			goodCommitOffset: r.goodCommitOffset,
+			badCommitOffset:  r.badCommitOffset,
+
+			commits: r.commits,
+
+			skippedCommits: r.skippedCommits,
+
+			posterior: r.posterior,
+
+			candidates: r.candidates,
+
+			log: logrus.NewEntry(logger),
+		}
+		hypothetical.applyVerdict(commitOffset, verdict)
+		if hypothetical.posterior == nil && hypothetical.getNearestUntestedCommit(hypothetical.goodCommitOffset+1) == -1 {
+			// The bisection would be done
+			continue
+		}
+
+		for _, nextCommit := range hypothetical.getNextCommits(r.parentJob.Parallelism) {
+			commitHash := getActualCommit(r.commits[nextCommit], r.parentJob.commitReplacements)
+			if nextCommit != commitOffset && !r.parentJob.isImageBuilt(r.parentJob.getDockerImageOfCommit(commitHash)) {
+				commitOffsets = append(commitOffsets, nextCommit)
+			}
+		}
 	}
+	return commitOffsets
+}
 
-	r.lastRunningSystem = rs
+// buildSpeculatively builds the image of the commit with the passed offset in the passed window, if the job's limit of concurrent speculative builds allows it.
+// Since the build only refers to the passed window, the replica's commits may change while it is running.
+func (r *replica) buildSpeculatively(window commitWindow, commitOffset int) {
+	if !r.parentJob.speculativeSemaphore.TryAcquire(1) {
+		r.log.Debugf("Skipping speculative build of commit %s, too many speculative builds running", window.commits[commitOffset])
+		return
+	}
+	defer r.parentJob.speculativeSemaphore.Release(1)
 
-	return rs, nil
+	r.log.Infof("Speculatively building commit %s", window.commits[commitOffset])
+	if _, _, err := r.buildImage(window, commitOffset, r.speculativeRepoPaths); err != nil {
+		r.log.Warnf("Speculative build of commit %s failed - %v", window.commits[commitOffset], err)
+	}
+}
+
+// buildImage builds the image of the commit with the passed offset in the passed window if it wasn't built yet and returns the hash of the built commit.
+// The build uses one of the repo copies in the passed pool.
+// If the commit breaks the build, it is replaced by another commit of the window and the returned boolean is false.
+// Builds failing transiently are retried up to the job's BuildRetries times. If they keep failing, a skippedCommitError is returned, since it isn't known whether the commit breaks the build.
+func (r *replica) buildImage(window commitWindow, commitOffset int, repoPaths chan string) (string, bool, error) {
+	backoff := buildRetryBackoff
+	for retry := 0; ; retry++ {
+		commitHash, ok, failure, err := r.attemptImageBuild(window, commitOffset, repoPaths)
+		if err != nil || failure == nil {
+			return commitHash, ok, err
+		}
+
+		// Transient failures aren't caused by the commit, so it mustn't be avoided
+		if retry >= *r.parentJob.BuildRetries {
+			r.log.Warnf("Image build for commit hash %s failed transiently %d times, skipping commit - %s", commitHash, retry+1, failure.reason)
+			return commitHash, false, skippedCommitError{
+				commitHash: commitHash,
+				reason:     fmt.Sprintf("image build failed transiently %d times - %s", retry+1, failure.reason),
+			}
+		}
+		// The build lock and the repo copy are released while waiting, such that other builds aren't blocked
+		r.log.Warnf("Image build for commit hash %s failed transiently, retrying in %s - %s", commitHash, backoff, failure.reason)
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+// attemptImageBuild builds the image of the commit with the passed offset in the passed window once, see buildImage.
+// If the build failed transiently, the returned buildFailure states why.
+func (r *replica) attemptImageBuild(window commitWindow, commitOffset int, repoPaths chan string) (string, bool, *buildFailure, error) {
+	commitHash := getActualCommit(window.commits[commitOffset], r.parentJob.commitReplacements)
+	imageName := r.parentJob.getDockerImageOfCommit(commitHash)
+
+	newLock := &sync.Mutex{}
+	l, _ := r.parentJob.imagesBuilding.LoadOrStore(commitHash, newLock)
+	lock := l.(*sync.Mutex)
+	lock.Lock()
+	defer lock.Unlock()
+
+	if _, ok := r.parentJob.failedBuilds.Load(commitHash); ok {
+		r.log.Infof("Build of commit %s failed before, not rebuilding it", commitHash)
+		return commitHash, false, nil, nil
+	}
+
+	if r.parentJob.isImageBuilt(imageName) {
+		if _, ok := r.parentJob.commitReplacements.Load(commitHash); ok {
+			// Commit breaks the build, init another system
+			r.log.Warnf("Image for commit hash %s reported to be broken, reattempting to init next system.", commitHash)
+			return commitHash, false, nil, nil
+		}
+		// Image has been built - reuse it
+		r.log.Infof("Image %s of commit %s already built, reusing image", imageName, commitHash)
+		return commitHash, true, nil, nil
+	}
+
+	// Get a copy of the repo which is not used by another build
+	repoPath := <-repoPaths
+	defer func() { repoPaths <- repoPath }()
+
+	// Checkout new commit
+	cmd := exec.Command("sh", "-c", fmt.Sprintf("git add . && git reset --hard %s", commitHash))
+	cmd.Dir = repoPath
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return "", false, nil, errors.Join(fmt.Errorf("git checkout of hash %s at %s failed for replica %d, output: %s", commitHash, repoPath, r.index, out), err)
+	}
+
+	// Update all submodules
+	cmd = exec.Command("git", "submodule", "update", "--init", "--recursive")
+	cmd.Dir = repoPath
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return "", false, nil, errors.Join(fmt.Errorf("git submodule update at %s failed for replica %d, output: %s", repoPath, r.index, out), err)
+	}
+
+	// Create docker client
+	apiClient, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
+	if err != nil {
+		return "", false, nil, errors.Join(fmt.Errorf("docker client creation failed for replica %d", r.index), err)
+	}
+	defer apiClient.Close()
+
+	// Image has not been built yet
+	// TODO: Have to ensure there is no dockerfile being overwritten in dest repo
+	os.WriteFile(path.Join(repoPath, "Dockerfile"), []byte(r.parentJob.dockerfileString), 0777)
+
+	r.log.Infof("Building image %s of commit %s", imageName, commitHash)
+	failure, err := r.runImageBuild(apiClient, repoPath, imageName, commitHash)
+	if err != nil {
+		return "", false, nil, err
+	}
+
+	if failure == nil {
+		r.parentJob.setImageBuilt(imageName)
+		return commitHash, true, nil, nil
+	}
+
+	if !failure.transient {
+		r.log.Warnf("Image build of %s for commit hash %s failed, avoiding commit from now on. Reason: %s, build log:\n%s", imageName, commitHash, failure.reason, failure.log)
+		if err := r.handleFailedBuild(window, commitOffset, commitHash, *failure); err != nil {
+			return commitHash, false, nil, err
+		}
+		// Set to true s.t. waiting replicas don't attempt to rebuild
+		r.parentJob.setImageBuilt(imageName)
+		return commitHash, false, nil, nil
+	}
+	return commitHash, false, failure, nil
+}
+
+// runImageBuild builds the image with the passed name of the commit checked out in the passed repo copy once.
+// If the build failed, the returned buildFailure states why. Errors of the docker daemon or while transferring the build output are transient failures.
+func (r *replica) runImageBuild(apiClient *client.Client, repoPath, imageName, commitHash string) (*buildFailure, error) {
+	ctx, err := archive.TarWithOptions(repoPath, &archive.TarOptions{})
+	if err != nil {
+		return nil, errors.Join(fmt.Errorf("tar creation of dockerfile for commit hash %s failed for replica %d", commitHash, r.index), err)
+	}
+	buildRes, err := apiClient.ImageBuild(context.Background(), ctx, types.ImageBuildOptions{
+		Tags:        []string{imageName},
+		ForceRemove: true,
+		Labels:      map[string]string{"biscepter": "1"},
+	})
+	if err != nil {
+		// The response has no body if the build couldn't be started
+		return &buildFailure{
+			transient: true,
+			reason:    err.Error(),
+		}, nil
+	}
+	defer buildRes.Body.Close()
+
+	// Wait for build to be done
+	out, err := io.ReadAll(buildRes.Body)
+	if err != nil {
+		return &buildFailure{
+			transient: true,
+			reason:    err.Error(),
+			log:       getLogExcerpt(string(out)),
+		}, nil
+	}
+	logrus.Tracef("Image build output:\n%s", string(out))
+
+	return parseBuildOutput(out), nil
+}
+
+// handleFailedBuild makes note of the passed commit, tested for the commit with the passed offset in the passed window, breaking the build due to the passed failure.
+// In build mode, the failure is remembered as the commit's verdict, otherwise the commit is replaced, or a skippedCommitError returned if it can't be.
+func (r *replica) handleFailedBuild(window commitWindow, commitOffset int, commitHash string, failure buildFailure) error {
+	if r.parentJob.Mode == BuildMode {
+		r.parentJob.failedBuilds.Store(commitHash, true)
+		return nil
+	}
+	return r.replaceCommit(window, commitOffset, commitHash, BuildFailed, failure.reason, failure.log)
+}
+
+// rateByVerdictCommand rates the passed running system based on the exit code of the job's verdict command, which is executed inside the system's container.
+// If the command couldn't be run or exited with an invalid exit code, the system is skipped and the error is appended to the verdict's output.
+func (r *replica) rateByVerdictCommand(rs RunningSystem) {
+	verdict, output, err := r.runVerdictCommand(rs)
+	if err != nil {
+		r.log.Errorf("Failed to get verdict of commit %s, skipping it - %v", rs.Commit, err)
+		verdict = Skip
+		if output != "" && !strings.HasSuffix(output, "\n") {
+			output += "\n"
+		}
+		output += fmt.Sprintf("failed to get verdict - %v", err)
+	}
+	r.log.Infof("Verdict command rated commit %s as %s", rs.Commit, verdict)
+
+	rs.verdictOutput = output
+	rs.Rate(verdict)
+}
+
+// runVerdictCommand executes the job's verdict command inside the container of the passed running system.
+// It returns the verdict resulting from the command's exit code as well as the command's combined stdout and stderr.
+func (r *replica) runVerdictCommand(rs RunningSystem) (Verdict, string, error) {
+	// Create docker client
+	apiClient, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
+	if err != nil {
+		return 0, "", errors.Join(fmt.Errorf("docker client creation failed for replica %d", r.index), err)
+	}
+	defer apiClient.Close()
+
+	execResp, err := apiClient.ContainerExecCreate(context.Background(), rs.containerName, types.ExecConfig{
+		Cmd:          []string{"sh", "-c", r.parentJob.VerdictCommand},
+		AttachStdout: true,
+		AttachStderr: true,
+	})
+	if err != nil {
+		return 0, "", errors.Join(fmt.Errorf("exec creation in container %s failed for replica %d", rs.containerName, r.index), err)
+	}
+
+	attachResp, err := apiClient.ContainerExecAttach(context.Background(), execResp.ID, types.ExecStartCheck{})
+	if err != nil {
+		return 0, "", errors.Join(fmt.Errorf("exec attach in container %s failed for replica %d", rs.containerName, r.index), err)
+	}
+	defer attachResp.Close()
+
+	// Wait for the command to finish
+	out := new(bytes.Buffer)
+	if _, err := stdcopy.StdCopy(out, out, attachResp.Reader); err != nil {
+		return 0, out.String(), errors.Join(fmt.Errorf("reading output of exec in container %s failed for replica %d", rs.containerName, r.index), err)
+	}
+
+	inspectResp, err := apiClient.ContainerExecInspect(context.Background(), execResp.ID)
+	if err != nil {
+		return 0, out.String(), errors.Join(fmt.Errorf("exec inspection in container %s failed for replica %d", rs.containerName, r.index), err)
+	}
+	r.log.Debugf("Verdict command exited with code %d, output: %s", inspectResp.ExitCode, out)
+
+	verdict, err := VerdictFromExitCode(inspectResp.ExitCode)
+	return verdict, out.String(), err
+}
+
+// getNextCommits returns the offsets of the next commits which should be tested in parallel, of which there are at most count.
+// The returned commits split the remaining commits into count+1 parts of roughly equal size.
+func (r replica) getNextCommits(count int) []int {
+	if r.offendingCommit != nil {
+		return r.getVerificationCommits(count)
+	}
+	if count <= 1 {
+		return []int{r.getNextCommit()}
+	}
+	if r.posterior != nil {
+		return r.getMostInformativeCommits(count)
+	}
+	if r.candidates != nil {
+		return r.getHalvingCommits(count)
+	}
+
+	chosen := make(map[int]bool)
+	commitOffsets := []int{}
+	pick := func(commitOffset int) bool {
+		if !r.isUntested(commitOffset) || chosen[commitOffset] {
+			return false
+		}
+		chosen[commitOffset] = true
+		commitOffsets = append(commitOffsets, commitOffset)
+		return true
+	}
+
+	commitsLeft := r.badCommitOffset - r.goodCommitOffset
+	for i := 1; i <= count; i++ {
+		target := r.goodCommitOffset + commitsLeft*i/(count+1)
+		for j := 0; j < commitsLeft; j++ {
+			if pick(target+j) || pick(target-j) {
+				break
+			}
+		}
+	}
+
+	sort.Ints(commitOffsets)
+	r.log.Debugf("Good commit %d, Bad commit %d, next commits %v", r.goodCommitOffset, r.badCommitOffset, commitOffsets)
+	return commitOffsets
 }
 
 // getNextCommit returns the next commit which should be used for bisection
 func (r replica) getNextCommit() int {
+	if r.posterior != nil {
+		return r.getMostInformativeCommit()
+	}
+	if r.candidates != nil {
+		if commitOffsets := r.getHalvingCommits(1); len(commitOffsets) != 0 {
+			return commitOffsets[0]
+		}
+		return -1
+	}
+
 	nextCommit := (r.goodCommitOffset + r.badCommitOffset) / 2
+	if r.skippedCommits[nextCommit] {
+		nextCommit = r.getNearestUntestedCommit(nextCommit)
+	}
 
 	// Find closest cached build
 	offset := 0
 	for i := 0; i < r.badCommitOffset-nextCommit; i++ {
 		commitAbove := r.parentJob.getDockerImageOfCommit(r.commits[nextCommit+i])
-		commitBelow := r.parentJob.getDockerImageOfCommit(r.commits[nextCommit-i])
+		commitBelow := ""
+		if nextCommit-i > r.goodCommitOffset {
+			commitBelow = r.parentJob.getDockerImageOfCommit(r.commits[nextCommit-i])
+		}
 
-		if r.parentJob.builtImages[commitAbove] {
+		if r.parentJob.isImageBuilt(commitAbove) && !r.skippedCommits[nextCommit+i] {
 			// If a commit above the middle is built
 			offset = i
 			break
-		} else if r.parentJob.builtImages[commitBelow] && nextCommit-i > r.goodCommitOffset {
+		} else if r.parentJob.isImageBuilt(commitBelow) && !r.skippedCommits[nextCommit-i] && nextCommit-i > r.goodCommitOffset {
 			// If a commit below the middle is built. Since nextCommit rounds down, we have to check we're not testing the same commit again
 			offset = -i
 			break
@@ -397,7 +1158,7 @@ func (r replica) getNextCommit() int {
 		// Get the fraction of cached vs uncached commits
 		cached := 0
 		for i := r.goodCommitOffset + 1; i < r.badCommitOffset-1; i++ {
-			if r.parentJob.builtImages[r.parentJob.getDockerImageOfCommit(r.commits[i])] {
+			if r.parentJob.isImageBuilt(r.parentJob.getDockerImageOfCommit(r.commits[i])) {
 				cached++
 			}
 		}
@@ -424,56 +1185,145 @@ func (r replica) getNextCommit() int {
 	return nextCommit + offset
 }
 
+// getNearestUntestedCommit returns the offset of the commit closest to the passed offset which lies between the good and the bad commit and was not skipped.
+// If every commit in between the good and the bad commit was skipped, -1 is returned.
+func (r replica) getNearestUntestedCommit(commitOffset int) int {
+	for i := 0; i < r.badCommitOffset-r.goodCommitOffset; i++ {
+		if above := commitOffset + i; r.isUntested(above) {
+			return above
+		}
+		if below := commitOffset - i; r.isUntested(below) {
+			return below
+		}
+	}
+	return -1
+}
+
+// isUntested returns whether the commit with the passed offset lies strictly between the good and the bad commit and was not skipped.
+// If the full history is bisected, it instead returns whether the commit is a candidate other than the bad commit and was not skipped
+func (r replica) isUntested(commitOffset int) bool {
+	if r.candidates != nil {
+		return r.isRelevant(commitOffset) && !r.skippedCommits[commitOffset]
+	}
+	return commitOffset > r.goodCommitOffset && commitOffset < r.badCommitOffset && !r.skippedCommits[commitOffset]
+}
+
 // getOffendingCommit returns the offending commit for the issue bisected by the replica if it was found.
 // If no offending commit was yet found, returns nil
 func (r *replica) getOffendingCommit() *OffendingCommit {
+	if r.offendingCommit != nil {
+		return r.finishVerification()
+	}
+
+	confidence := 1.0
+	if r.posterior != nil {
+		// Offending commit not yet found with enough confidence
+		mostLikely, probability := r.getMostLikelyCommit()
+		if probability < r.parentJob.Probabilistic.Confidence && r.getNearestUntestedCommit(r.goodCommitOffset+1) != -1 {
+			return nil
+		}
+		confidence = probability
+		r.goodCommitOffset = mostLikely - 1
+		r.badCommitOffset = mostLikely
+	}
+
 	// Offending commit not yet found
-	if r.badCommitOffset > r.goodCommitOffset+1 {
+	if r.getNearestUntestedCommit(r.goodCommitOffset+1) != -1 {
 		return nil
 	}
 
+	// All commits left in between the good and the bad commit were skipped, so any of them could be the offending commit
+	var skippedCommits []string
+	for i := r.goodCommitOffset + 1; i < r.badCommitOffset; i++ {
+		if r.candidates == nil || r.candidates.has(i) {
+			skippedCommits = append(skippedCommits, getActualCommit(r.commits[i], r.parentJob.commitReplacements))
+		}
+	}
+
 	commitHash := getActualCommit(r.commits[r.badCommitOffset], r.parentJob.commitReplacements)
 	prevCommitHash := getActualCommit(r.commits[r.badCommitOffset-1], r.parentJob.commitReplacements)
 
-	// Get all commits that alias to the current one
-	curCommit := commitHash
-	for {
-		found := false
-		r.parentJob.commitReplacements.Range(
-			func(key, value any) bool {
-				commit := key.(string)
-				replacement := value.(string)
-				if replacement == curCommit {
-					curCommit = commit
+	// Commits which broke the build could have introduced the issue as well
+	var ambiguousRange *CommitRange
+	if r.candidates == nil {
+		ambiguousRange = r.getAmbiguousRange()
+		if ambiguousRange != nil {
+			for _, commit := range ambiguousRange.Commits {
+				if commitOffset := slices.Index(r.commits, commit); commit != commitHash && (commitOffset <= r.goodCommitOffset || commitOffset >= r.badCommitOffset) {
 					r.possibleOtherCommits = append(r.possibleOtherCommits, commit)
-					found = true
-					return false
 				}
-				return true
-			},
-		)
-		if !found {
-			break
+			}
 		}
+	} else {
+		r.possibleOtherCommits = append(r.possibleOtherCommits, getReplacedCommits(commitHash, r.parentJob.commitReplacements)...)
 	}
 
-	// TODO: Maybe toggle this off with a flag? Or specify a max depth of bisecting merges? Also document that octopus merges are not supported.
-	// TODO: Check if commit is a merge commit but no octopus commit, bisect merge branch if yes
+	_, isOctopusChainCommit := r.octopusMerges[commitHash]
 
-	mergeParent, err := getMergedParent(commitHash, prevCommitHash, r.repoPath)
-	if err != nil {
-		r.log.Errorf("Failed to get merge parent of %s - %v", commitHash, err)
+	var mergeParent string
+	var mergedCommits []string
+	if len(skippedCommits) != 0 {
+		r.log.Warnf("Only skipped commits are left between the good and bad commit, the offending commit could be any of %v or %s", skippedCommits, commitHash)
+		r.possibleOtherCommits = append(r.possibleOtherCommits, skippedCommits...)
+	} else if r.candidates == nil && !isOctopusChainCommit && !r.parentJob.MergePolicy.allowsDescent(len(r.mergePath)) {
+		// Report the commits merged by the offending commit instead of descending into them
+		var err error
+		mergedCommits, err = getMergedCommits(commitHash, r.repoPath, r.parentJob.Paths)
+		if err != nil {
+			r.log.Errorf("Failed to get commits merged by %s - %v", commitHash, err)
+		} else if len(mergedCommits) != 0 {
+			r.log.Infof("Offending commit %s is a merge commit, but the merge policy doesn't allow descending any further. Merged commits: %v", commitHash, mergedCommits)
+		}
+	} else if r.candidates == nil {
+		// Only descend into merges if we are certain about the offending commit and merged branches weren't already bisected as part of the full history
+		var err error
+		if len(r.parentJob.Paths) != 0 {
+			// The previous commit touching the paths isn't necessarily the merge commit's parent
+			if firstParent, err := getFirstParent(commitHash, r.repoPath); err != nil {
+				r.log.Errorf("Failed to get first parent of %s - %v", commitHash, err)
+			} else {
+				prevCommitHash = firstParent
+			}
+		}
+		mergeParent, err = getMergedParent(commitHash, prevCommitHash, r.repoPath)
+		if err != nil {
+			r.log.Errorf("Failed to get merge parent of %s - %v", commitHash, err)
+		} else if mergeParent == "" {
+			// Find out which of the parents of an octopus commit introduced the issue by bisecting a chain of regular merges
+			chain, err := createOctopusChain(commitHash, prevCommitHash, r.repoCopies)
+			if err != nil {
+				r.log.Errorf("Failed to split up octopus commit %s - %v", commitHash, err)
+			} else if chain != nil {
+				r.log.Infof("Offending commit %s is an octopus commit with %d merged parents, bisecting them one by one", commitHash, len(chain)-1)
+				if r.octopusMerges == nil {
+					r.octopusMerges = make(map[string]string)
+				}
+				for _, commit := range chain[1:] {
+					r.octopusMerges[commit] = commitHash
+				}
+				r.setCommits(chain)
+				return nil
+			}
+		}
 	}
 
 	if mergeParent != "" {
-		r.log.Infof("Offending commit %s is a merge commit. Merged parent: %s", commitHash, mergeParent)
-		var err error
-		r.commits, err = getCommitsBetween(prevCommitHash, mergeParent, r.repoPath)
+		mergeCommit := commitHash
+		if octopusCommit, ok := r.octopusMerges[commitHash]; ok {
+			// The merge commit was created when splitting up the octopus commit, which is the one that actually merged the parent
+			mergeCommit = octopusCommit
+		}
+		r.log.Infof("Offending commit %s is a merge commit. Merged parent: %s", mergeCommit, mergeParent)
+		r.mergePath = append(r.mergePath, MergeStep{
+			MergeCommit:  mergeCommit,
+			MergedParent: mergeParent,
+		})
+
+		commits, err := getCommitsBetween(prevCommitHash, mergeParent, r.repoPath, r.parentJob.Paths)
 		if err != nil {
 			r.log.Panicf("couldn't get replica's merge commits - %v", err)
 		}
-		r.goodCommitOffset = 0
-		r.badCommitOffset = len(r.commits) - 1
+		r.setCommits(commits)
 		return nil
 	}
 
@@ -500,52 +1350,169 @@ func (r *replica) getOffendingCommit() *OffendingCommit {
 		}
 	}
 
-	r.log.Infof("Found offending commit %s with offset %d. Message: %q, Date: %q, Author: %q", commitHash, r.badCommitOffset, commitMsg, commitDate, commitAuthor)
+	r.log.Infof("Found offending commit %s, the first %s commit, with offset %d and confidence %.3f. Message: %q, Date: %q, Author: %q", commitHash, r.parentJob.TermNew, r.badCommitOffset, confidence, commitMsg, commitDate, commitAuthor)
 
-	return &OffendingCommit{
+	failure := r.startupFailures[commitHash]
+	return r.verify(&OffendingCommit{
 		ReplicaIndex: r.index,
 
 		Commit:       commitHash,
 		CommitOffset: r.badCommitOffset,
+		Term:         r.parentJob.TermNew,
 
 		CommitMessage: commitMsg,
 		CommitDate:    commitDate,
 		CommitAuthor:  commitAuthor,
 
 		PossibleOtherCommits: r.possibleOtherCommits,
+		AmbiguousRange:       ambiguousRange,
+
+		Confidence: confidence,
+
+		Verdicts: r.verdicts,
+
+		MergePath:     r.mergePath,
+		MergedCommits: mergedCommits,
+
+		HealthcheckError: failure.healthcheckError,
+		ContainerLogs:    failure.containerLogs,
+	})
+}
+
+// getAmbiguousRange returns the range of commits which may have introduced the issue, given that the verdicts of the good and the bad commit may be those of their replacements,
+// and commits in between them may have been skipped. If only the bad commit may have introduced the issue, nil is returned.
+// The lock of waitingCond has to be held when calling this method.
+func (r replica) getAmbiguousRange() *CommitRange {
+	older := min(r.goodCommitOffset, r.getActualOffset(r.goodCommitOffset))
+	newer := max(r.badCommitOffset, r.getActualOffset(r.badCommitOffset))
+	if older == newer-1 {
+		return nil
+	}
+
+	return &CommitRange{
+		Older: r.commits[older],
+		Newer: r.commits[newer],
+
+		Commits: slices.Clone(r.commits[older+1 : newer+1]),
 	}
 }
 
-// replaceCommit makes note of the passed commit as breaking the build.
-// Once the function returns, a replacement commit will have been set in this job's replacementCommit map for the passed commit.
-//
-// Since it is assumed that the ends of the commits slice are commits that build, as they otherwise couldn't have been evaluated, this function panics if
+// getActualOffset returns the offset of the commit which is tested in place of the commit with the passed offset, see getActualCommit.
+// If the replacement isn't one of this replica's commits, the passed offset is returned.
+func (r replica) getActualOffset(commitOffset int) int {
+	if actualOffset := slices.Index(r.commits, getActualCommit(r.commits[commitOffset], r.parentJob.commitReplacements)); actualOffset != -1 {
+		return actualOffset
+	}
+	return commitOffset
+}
+
+// setCommits replaces the commits bisected by this replica with the passed commits, e.g. when descending into a merge, and restarts the bisection on them
+func (r *replica) setCommits(commits []string) {
+	r.commits = commits
+	r.goodCommitOffset = 0
+	r.badCommitOffset = len(r.commits) - 1
+	r.skippedCommits = make(map[int]bool)
+	if r.posterior != nil {
+		r.initPosterior()
+	}
+
+	r.saveCheckpoint()
+}
+
+// getWindow returns a snapshot of the commits this replica's bisection is currently restricted to.
+// The lock of waitingCond has to be held when calling this method.
+func (r replica) getWindow() commitWindow {
+	return commitWindow{
+		commits: r.commits,
+
+		goodCommitOffset: r.goodCommitOffset,
+		badCommitOffset:  r.badCommitOffset,
+
+		candidates: r.candidates,
+	}
+}
+
+// replaceCommit makes note of the passed commit, tested for the commit with the passed offset in the passed window, as breaking the build in the passed way due to the passed reason with the passed build log excerpt.
+// Once the function returns without an error, a replacement commit will have been set in this job's replacementCommit map for the passed commit.
 //
-//	commitOffset >= len(commits) - 1
-func (r *replica) replaceCommit(commitOffset int) {
-	if commitOffset >= len(r.commits)-1 {
-		logrus.Panicf("Passed commit offset %d to replaceCommit is too large! Max allowed length :%d", commitOffset, len(r.commits)-2)
-	}
-
-	// Get the offset of the actual commit to replace
-	cur := r.commits[commitOffset]
-	for {
-		if val, ok := r.parentJob.commitReplacements.Load(cur); ok {
-			cur = val.(string)
-			commitOffset++
-		} else {
-			break
-		}
+// The replacement is the commit of the window closest to the broken commit, searching in both directions, which isn't known to break the build.
+// If both the older and the newer commit at the closest distance qualify, the one whose image was already built is preferred, and the newer one otherwise.
+// If the job bisects the full history, only parents and children of the broken commit which are still candidates qualify.
+// If no commit of the window qualifies, a skippedCommitError is returned and the commit has to be skipped instead.
+func (r *replica) replaceCommit(window commitWindow, commitOffset int, commitHash string, kind FailureKind, reason, log string) error {
+	r.parentJob.commitReplacementsLock.Lock()
+	defer r.parentJob.commitReplacementsLock.Unlock()
+
+	if _, ok := r.parentJob.commitReplacements.Load(commitHash); ok {
+		// Another system of the commit already failed
+		return nil
+	}
+
+	// The broken commit may be a replacement of the commit with the passed offset
+	if brokenOffset := slices.Index(window.commits, commitHash); brokenOffset != -1 {
+		commitOffset = brokenOffset
 	}
 
-	next := r.commits[commitOffset+1]
+	replacementOffset := r.getReplacementOffset(window, commitOffset)
+	if replacementOffset == -1 {
+		return skippedCommitError{commitHash: commitHash, reason: fmt.Sprintf("no commit left to replace it with - %s", reason)}
+	}
+	replacement := window.commits[replacementOffset]
 
 	// Store in replacements file for reuse in later runs
-	r.parentJob.commitReplacementsBackupFile.WriteString(fmt.Sprintf("%s:%s,", cur, next))
+	if err := r.parentJob.writeReplacement(CommitReplacement{
+		Commit:      commitHash,
+		Replacement: replacement,
+		Kind:        kind,
+		Reason:      reason,
+		Log:         log,
+	}); err != nil {
+		r.log.Errorf("Failed to store replacement of commit %s - %v", commitHash, err)
+	}
+
+	r.log.Debugf("Adding new replacement: %s -> %s (offset %d -> %d)", commitHash, replacement, commitOffset, replacementOffset)
 
-	r.log.Debugf("Adding new replacement: %s -> %s", cur, next)
+	r.parentJob.commitReplacements.Store(commitHash, replacement)
+	return nil
+}
+
+// getReplacementOffset returns the offset of the commit strictly inside of the passed window, which is closest to the passed broken commit and not known to break the build.
+// If the window has candidates, only candidates which are a parent or child of the broken commit are considered.
+// At the same distance, commits whose image was already built are preferred, and newer commits otherwise. If no such commit exists, -1 is returned.
+// The job's commitReplacementsLock has to be held when calling this method, such that no cycles of replacements are created.
+func (r *replica) getReplacementOffset(window commitWindow, brokenOffset int) int {
+	lowerBound, upperBound := window.goodCommitOffset, window.badCommitOffset
+	isReplaceable := func(commitOffset int) bool {
+		if commitOffset <= lowerBound || commitOffset >= upperBound {
+			return false
+		}
+		if window.candidates != nil {
+			if !window.candidates.has(commitOffset) {
+				return false
+			}
+			if !r.parentJob.isParent(min(commitOffset, brokenOffset), max(commitOffset, brokenOffset)) {
+				return false
+			}
+		}
+		_, isBroken := r.parentJob.commitReplacements.Load(window.commits[commitOffset])
+		return !isBroken
+	}
 
-	r.parentJob.commitReplacements.Store(cur, next)
+	for distance := 1; brokenOffset-distance > lowerBound || brokenOffset+distance < upperBound; distance++ {
+		older, newer := brokenOffset-distance, brokenOffset+distance
+		switch {
+		case isReplaceable(older) && isReplaceable(newer):
+			if !r.parentJob.isImageBuilt(r.parentJob.getDockerImageOfCommit(window.commits[newer])) && r.parentJob.isImageBuilt(r.parentJob.getDockerImageOfCommit(window.commits[older])) {
+				return older
+			}
+			return newer
+		case isReplaceable(newer):
+			return newer
+		case isReplaceable(older):
+			return older
+		}
+	}
+	return -1
 }
 
 // A RunningSystem is a running system that is ready to be tested
@@ -554,37 +1521,110 @@ type RunningSystem struct {
 
 	Ports map[int]int // A mapping of the ports specified for the system under test to the ones they were mapped to locally
 
+	Commit string // The hash of the commit this system is running
+
+	Notes string // Optional notes about this system's verdict, e.g. how it was tested. Recorded alongside the verdict once the system is rated
+
 	parentReplica *replica
 
 	containerName string // The name of the container running this system
 
-	commit           string // The current commit
-	commitRootOffset int    // The offset of the current commit to the root commit
+	commitRootOffset int // The offset of the current commit to the root commit
+
+	verdictOutput string // The output of the verdict command which rated this system, if any
+
+	buildFailed bool // Whether building the image of this system's commit failed. Only set in build mode, where no container is started
+
+	startupFailure *startupFailure // Why this system failed to start up. Only set in startup mode, where the container is removed once the healthchecks were performed
 
-	wasRated bool // If this system was already specified to be either good or bad
+	skipped bool // Whether this system's commit couldn't be tested, e.g. because its build kept failing transiently. No container is started for such systems, which are rated as skipped
+
+	wasRated bool // If this system was already specified to be either good, bad or skipped
+}
+
+// A skippedCommitError is returned while starting a system if its commit can't be tested, e.g. because its build kept failing transiently,
+// or because it breaks the build and no commit is left to replace it with.
+// Such commits are skipped by the replica instead of being replaced, and nothing is persisted about them.
+type skippedCommitError struct {
+	commitHash string // The commit which can't be tested
+	reason     string // Why the commit can't be tested
+}
+
+func (e skippedCommitError) Error() string {
+	return fmt.Sprintf("commit %s can't be tested - %s", e.commitHash, e.reason)
+}
+
+// A startupFailure describes why a system failed to start up in startup mode
+type startupFailure struct {
+	healthcheckError string // The error of the healthcheck which failed
+	containerLogs    string // The last lines of the logs of the system's container
 }
 
 // IsGood tells biscepter that this running system is good.
-// If IsGood is called after the running system was already rated by a previous IsGood or IsBad method invocation, it will panic.
+// If IsGood is called after the running system was already rated by a previous IsGood, IsBad or IsSkip method invocation, it will panic.
 func (r *RunningSystem) IsGood() {
 	if r.wasRated {
 		panic(fmt.Sprintf("IsGood was called on running system of replica with index %d after it was already rated", r.ReplicaIndex))
 	}
 	r.wasRated = true
-	r.parentReplica.isGood(*r)
+	r.parentReplica.rate(*r, Good)
 }
 
 // IsBad tells biscepter that this running system is bad.
-// If IsBad is called after the running system was already rated by a previous IsGood or IsBad method invocation, it will panic.
+// If IsBad is called after the running system was already rated by a previous IsGood, IsBad or IsSkip method invocation, it will panic.
 func (r *RunningSystem) IsBad() {
 	if r.wasRated {
 		panic(fmt.Sprintf("IsBad was called on running system of replica with index %d after it was already rated", r.ReplicaIndex))
 	}
 	r.wasRated = true
-	r.parentReplica.isBad(*r)
+	r.parentReplica.rate(*r, Bad)
+}
+
+// IsSkip tells biscepter that this running system cannot be tested, e.g. because a required feature or fixture is missing.
+// The commit is then only avoided by this system's replica, which continues with the nearest untested commit instead.
+// If IsSkip is called after the running system was already rated by a previous IsGood, IsBad or IsSkip method invocation, it will panic.
+func (r *RunningSystem) IsSkip() {
+	if r.wasRated {
+		panic(fmt.Sprintf("IsSkip was called on running system of replica with index %d after it was already rated", r.ReplicaIndex))
+	}
+	r.wasRated = true
+	r.parentReplica.rate(*r, Skip)
+}
+
+// IsTerm rates this running system using one of the job's terms, i.e. TermOld, TermNew or "skip".
+// For example, if the job's terms are "broken" and "fixed", IsTerm("fixed") marks this system as showing the behaviour of the new commit.
+// If the term is none of the job's terms, an error is returned and the system stays unrated.
+// If IsTerm is called after the running system was already rated, it will panic.
+func (r *RunningSystem) IsTerm(term string) error {
+	verdict, err := r.parentReplica.parentJob.VerdictOfTerm(term)
+	if err != nil {
+		return err
+	}
+	r.Rate(verdict)
+	return nil
+}
+
+// Rate rates this running system using the passed verdict, calling either IsGood, IsBad or IsSkip.
+// If Rate is called after the running system was already rated, it will panic.
+func (r *RunningSystem) Rate(verdict Verdict) {
+	switch verdict {
+	case Good:
+		r.IsGood()
+	case Bad:
+		r.IsBad()
+	case Skip:
+		r.IsSkip()
+	default:
+		panic(fmt.Sprintf("invalid verdict %d passed for running system of replica with index %d", verdict, r.ReplicaIndex))
+	}
 }
 
 func (r RunningSystem) stop() error {
+	if r.skipped || (r.parentReplica != nil && r.parentReplica.parentJob.Mode != RunMode) {
+		// No container was started, or it was already removed
+		return nil
+	}
+
 	// Create docker client
 	apiClient, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
 	if err != nil {
@@ -602,12 +1642,45 @@ func (r RunningSystem) stop() error {
 type OffendingCommit struct {
 	ReplicaIndex int // The index of the bisected replica
 
-	Commit       string // The commit which introduced the issue. I.e. the oldest bad commit
-	CommitOffset int    // The offset to the initial commit of the commit which introduced the issue. I.e. the offset of the oldest bad commit
+	Cancelled bool // Whether the replica was cancelled using [Job.CancelReplica] before finding its offending commit. If set, all other fields apart from ReplicaIndex are empty
+
+	Commit       string // The commit which introduced the issue. I.e. the oldest bad commit, or the oldest good commit if the job is reversed
+	CommitOffset int    // The offset to the initial commit of the commit which introduced the issue. I.e. the offset of the oldest bad commit, or the oldest good commit if the job is reversed
+	Term         string // The job's term for the behaviour of the offending commit, i.e. its TermNew
 
 	CommitMessage string // The message of the offending commit
 	CommitDate    string // The date of the offending commit
 	CommitAuthor  string // The author of the offending commit
 
-	PossibleOtherCommits []string // Other possible offending commits. Set if there were build failures causing uncertainty in the exact offending commit
+	PossibleOtherCommits []string     // Other possible offending commits. Set if there were build failures or skipped commits causing uncertainty in the exact offending commit
+	AmbiguousRange       *CommitRange // The range of commits which may have introduced the issue, if the offending commit is next to commits which broke the build or were skipped. Not set if the job bisects the full history
+
+	Confidence float64 // The probability of Commit being the offending commit. Always 1, unless the job bisects probabilistically
+
+	Verdicts []VerdictRecord // Every verdict the replica received during the bisection, in order
+
+	MergePath     []MergeStep // The merge commits the bisection descended into to find the offending commit, outermost first
+	MergedCommits []string    // The commits merged by the offending commit, if it is a merge commit which wasn't descended into due to the job's merge policy
+
+	Status          VerificationStatus // Whether the offending commit was verified, or inconsistent verdicts were received. See [VerificationConfig]
+	Inconsistencies []string           // Descriptions of the inconsistent verdicts the replica received, e.g. commits which were rated differently when verifying the offending commit
+	Restarts        int                // How many times the bisection was restarted because verifying the offending commit failed
+
+	HealthcheckError string // The error of the last failed healthcheck of the offending commit. Only set in startup mode
+	ContainerLogs    string // The last lines of the logs of the offending commit's container after its healthcheck failed. Only set in startup mode
+}
+
+// A CommitRange is a range of consecutive commits of a replica's commits, like older..newer in git's notation.
+// Since the verdicts of commits breaking the build are those of their replacements, the commits in between a replacement and the commit it replaced can't be told apart.
+type CommitRange struct {
+	Older string // The newest commit before the range, i.e. the newest commit which was tested to have the old behaviour
+	Newer string // The newest commit of the range, i.e. the oldest commit which was tested to have the new behaviour
+
+	Commits []string // The commits of the range, oldest first. Ends with Newer
+}
+
+// A MergeStep represents a merge commit which was found to be offending and descended into during the bisection.
+type MergeStep struct {
+	MergeCommit  string `json:"mergeCommit"`  // The merge commit which merged the offending commit. For octopus commits, this is the octopus commit itself
+	MergedParent string `json:"mergedParent"` // The merged parent of the merge commit which introduced the issue, and in whose history the bisection continued
 }
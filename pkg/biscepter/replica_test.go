@@ -1,6 +1,8 @@
 package biscepter
 
 import (
+	"os"
+	"sync"
 	"testing"
 
 	"github.com/sirupsen/logrus"
@@ -13,15 +15,20 @@ func TestGetNextCommit(t *testing.T) {
 		badCommitOffset  int
 		commits          []string
 		built            []string
+		skipped          []int
 		buildCost        float64
 
 		expectedIndex int
 	}{
-		{0, 6, []string{"padl", "a", "b", "c", "d", "e", "padr"}, []string{"c"}, 1e10, 3},
-		{0, 6, []string{"padl", "a", "b", "c", "d", "e", "padr"}, []string{"b"}, 1e10, 2},
-		{0, 6, []string{"padl", "a", "b", "c", "d", "e", "padr"}, []string{"d"}, 1e10, 4},
-		{0, 6, []string{"padl", "a", "b", "c", "d", "e", "padr"}, []string{"a", "d"}, 1e10, 4},
-		{0, 6, []string{"padl", "a", "b", "c", "d", "e", "padr"}, []string{"b", "e"}, 1e10, 2},
+		{0, 6, []string{"padl", "a", "b", "c", "d", "e", "padr"}, []string{"c"}, nil, 1e10, 3},
+		{0, 6, []string{"padl", "a", "b", "c", "d", "e", "padr"}, []string{"b"}, nil, 1e10, 2},
+		{0, 6, []string{"padl", "a", "b", "c", "d", "e", "padr"}, []string{"d"}, nil, 1e10, 4},
+		{0, 6, []string{"padl", "a", "b", "c", "d", "e", "padr"}, []string{"a", "d"}, nil, 1e10, 4},
+		{0, 6, []string{"padl", "a", "b", "c", "d", "e", "padr"}, []string{"b", "e"}, nil, 1e10, 2},
+		{0, 6, []string{"padl", "a", "b", "c", "d", "e", "padr"}, []string{}, []int{3}, 1e10, 4},
+		{0, 6, []string{"padl", "a", "b", "c", "d", "e", "padr"}, []string{}, []int{3, 4}, 1e10, 2},
+		{0, 6, []string{"padl", "a", "b", "c", "d", "e", "padr"}, []string{"d"}, []int{4}, 1e10, 3},
+		{0, 6, []string{"padl", "a", "b", "c", "d", "e", "padr"}, []string{"b", "e"}, []int{2}, 1e10, 5},
 	}
 
 	for i, v := range values {
@@ -29,18 +36,360 @@ func TestGetNextCommit(t *testing.T) {
 			goodCommitOffset: v.goodCommitOffset,
 			badCommitOffset:  v.badCommitOffset,
 			commits:          v.commits,
+			skippedCommits:   make(map[int]bool),
 			log:              logrus.NewEntry(logrus.StandardLogger()),
 			parentJob: &Job{
 				BuildCost:   v.buildCost,
 				builtImages: make(map[string]bool),
 			},
 		}
+		for _, offset := range v.skipped {
+			rep.skippedCommits[offset] = true
+		}
 		for _, image := range v.built {
-			rep.parentJob.builtImages[rep.parentJob.getDockerImageOfCommit(image)] = true
+			rep.parentJob.setImageBuilt(rep.parentJob.getDockerImageOfCommit(image))
 		}
 
 		logrus.SetLevel(logrus.TraceLevel)
 
-		assert.Equalf(t, v.expectedIndex, rep.getNextCommit(), "GetNextCommit returned wrong offset for test %d; goodCommit: %d, badCommit: %d, commits: %v, built: %v, buildCost: %f", i, v.goodCommitOffset, v.badCommitOffset, v.commits, v.built, v.buildCost)
+		assert.Equalf(t, v.expectedIndex, rep.getNextCommit(), "GetNextCommit returned wrong offset for test %d; goodCommit: %d, badCommit: %d, commits: %v, built: %v, skipped: %v, buildCost: %f", i, v.goodCommitOffset, v.badCommitOffset, v.commits, v.built, v.skipped, v.buildCost)
+	}
+}
+
+func TestGetNearestUntestedCommit(t *testing.T) {
+	values := []struct {
+		goodCommitOffset int
+		badCommitOffset  int
+		skipped          []int
+		commitOffset     int
+
+		expectedIndex int
+	}{
+		{0, 6, nil, 3, 3},
+		{0, 6, []int{3}, 3, 4},
+		{0, 6, []int{3, 4}, 3, 2},
+		{0, 6, []int{1, 2, 3, 4}, 3, 5},
+		{0, 6, []int{1, 2, 3, 4, 5}, 3, -1},
+		{2, 3, nil, 3, -1},
+		{2, 4, nil, 2, 3},
+	}
+
+	for i, v := range values {
+		rep := replica{
+			goodCommitOffset: v.goodCommitOffset,
+			badCommitOffset:  v.badCommitOffset,
+			skippedCommits:   make(map[int]bool),
+		}
+		for _, offset := range v.skipped {
+			rep.skippedCommits[offset] = true
+		}
+
+		assert.Equalf(t, v.expectedIndex, rep.getNearestUntestedCommit(v.commitOffset), "getNearestUntestedCommit returned wrong offset for test %d; goodCommit: %d, badCommit: %d, skipped: %v, commitOffset: %d", i, v.goodCommitOffset, v.badCommitOffset, v.skipped, v.commitOffset)
+	}
+}
+
+func TestGetNextCommits(t *testing.T) {
+	values := []struct {
+		goodCommitOffset int
+		badCommitOffset  int
+		skipped          []int
+		count            int
+
+		expectedIndices []int
+	}{
+		{0, 8, nil, 2, []int{2, 5}},
+		{0, 8, nil, 3, []int{2, 4, 6}},
+		{0, 8, []int{4}, 3, []int{2, 5, 6}},
+		{0, 3, nil, 3, []int{1, 2}},
+		{4, 16, nil, 3, []int{7, 10, 13}},
+	}
+
+	for i, v := range values {
+		rep := replica{
+			goodCommitOffset: v.goodCommitOffset,
+			badCommitOffset:  v.badCommitOffset,
+			skippedCommits:   make(map[int]bool),
+			log:              logrus.NewEntry(logrus.StandardLogger()),
+		}
+		for _, offset := range v.skipped {
+			rep.skippedCommits[offset] = true
+		}
+
+		assert.Equalf(t, v.expectedIndices, rep.getNextCommits(v.count), "getNextCommits returned wrong offsets for test %d; goodCommit: %d, badCommit: %d, skipped: %v, count: %d", i, v.goodCommitOffset, v.badCommitOffset, v.skipped, v.count)
+	}
+}
+
+func TestGetSpeculativeCommits(t *testing.T) {
+	values := []struct {
+		goodCommitOffset int
+		badCommitOffset  int
+		built            []string
+		commitOffset     int
+
+		expectedIndices []int
+	}{
+		{0, 8, nil, 4, []int{6, 2}},
+		{0, 8, []string{"g"}, 4, []int{2}},
+		{0, 8, []string{"b", "c", "g"}, 4, []int{}},
+		{3, 5, nil, 4, []int{}},
+		{0, 2, nil, 1, []int{}},
+		{0, 3, nil, 1, []int{2}},
+	}
+
+	for i, v := range values {
+		rep := replica{
+			goodCommitOffset: v.goodCommitOffset,
+			badCommitOffset:  v.badCommitOffset,
+			commits:          []string{"padl", "a", "b", "c", "d", "e", "f", "g", "padr"},
+			skippedCommits:   make(map[int]bool),
+			log:              logrus.NewEntry(logrus.StandardLogger()),
+			parentJob: &Job{
+				BuildCost:          1e10,
+				builtImages:        make(map[string]bool),
+				commitReplacements: &sync.Map{},
+			},
+		}
+		for _, image := range v.built {
+			rep.parentJob.setImageBuilt(rep.parentJob.getDockerImageOfCommit(image))
+		}
+
+		assert.Equalf(t, v.expectedIndices, rep.getSpeculativeCommits(v.commitOffset), "getSpeculativeCommits returned wrong offsets for test %d; goodCommit: %d, badCommit: %d, built: %v, commitOffset: %d", i, v.goodCommitOffset, v.badCommitOffset, v.built, v.commitOffset)
+	}
+}
+
+func TestSeedVerdicts(t *testing.T) {
+	// main: G - A - B - M - C - D
+	//            \     /
+	//             F1-F2
+	repoPath, commits := createTestRepo(t, []string{"g", "a"})
+	runGit(t, repoPath, "checkout", "-q", "-b", "feature")
+	f1 := commitFile(t, repoPath, "f1")
+	f2 := commitFile(t, repoPath, "f2")
+	runGit(t, repoPath, "checkout", "-q", "main")
+	b := commitFile(t, repoPath, "b")
+	runGit(t, repoPath, "merge", "-q", "--no-ff", "-m", "merge", "feature")
+	m := runGit(t, repoPath, "rev-parse", "HEAD")
+	c := commitFile(t, repoPath, "c")
+	d := commitFile(t, repoPath, "d")
+	runGit(t, repoPath, "checkout", "-q", "-b", "unrelated", commits[0])
+	unrelated := commitFile(t, repoPath, "u")
+
+	newReplica := func(reverse bool) *replica {
+		firstParentCommits := []string{commits[0], commits[1], b, m, c, d}
+		return &replica{
+			parentJob:        &Job{Reverse: reverse},
+			repoPath:         repoPath,
+			commits:          firstParentCommits,
+			goodCommitOffset: 0,
+			badCommitOffset:  len(firstParentCommits) - 1,
+			skippedCommits:   make(map[int]bool),
+			log:              logrus.NewEntry(logrus.StandardLogger()),
+		}
+	}
+
+	values := []struct {
+		name     string
+		reverse  bool
+		verdicts []VerdictRecord
+
+		goodCommitOffset int
+		badCommitOffset  int
+		mappedCommits    []string
+	}{
+		{"First-parent commits", false, []VerdictRecord{{Commit: commits[1], Verdict: Good}, {Commit: c, Verdict: Bad}}, 1, 4, []string{commits[1], c}},
+		{"Good merged commit maps to nearest ancestor", false, []VerdictRecord{{Commit: f2, Verdict: Good}}, 1, 5, []string{commits[1]}},
+		{"Bad merged commit maps to merge", false, []VerdictRecord{{Commit: f1, Verdict: Bad}}, 0, 3, []string{m}},
+		{"Reversed job", true, []VerdictRecord{{Commit: f1, Verdict: Good}, {Commit: f2, Verdict: Bad}}, 1, 3, []string{m, commits[1]}},
+		{"Abbreviated hash", false, []VerdictRecord{{Commit: b[:8], Verdict: Good}}, 2, 5, []string{b}},
+		{"Unrelated commits are ignored", false, []VerdictRecord{{Commit: unrelated, Verdict: Bad}, {Commit: "doesnotexist", Verdict: Good}, {Commit: f1, Verdict: Skip}}, 0, 5, []string{}},
+	}
+
+	for _, v := range values {
+		t.Run(v.name, func(t *testing.T) {
+			rep := newReplica(v.reverse)
+			rep.seedVerdicts(v.verdicts)
+			assert.Equal(t, v.goodCommitOffset, rep.goodCommitOffset, "Wrong good commit offset")
+			assert.Equal(t, v.badCommitOffset, rep.badCommitOffset, "Wrong bad commit offset")
+
+			mappedCommits := []string{}
+			for _, verdict := range rep.verdicts {
+				mappedCommits = append(mappedCommits, verdict.Commit)
+			}
+			assert.Equal(t, v.mappedCommits, mappedCommits, "Wrong commits of recorded verdicts")
+		})
+	}
+}
+
+func TestReplaceCommit(t *testing.T) {
+	commits := []string{"padl", "a", "b", "c", "d", "e", "f", "g", "padr"}
+
+	values := []struct {
+		name             string
+		goodCommitOffset int
+		badCommitOffset  int
+		replacements     map[string]string
+		built            []string
+		commitOffset     int
+		commitHash       string
+
+		expectedReplacement string // Empty if the commit should be skipped
+	}{
+		{"Newer commit by default", 0, 8, nil, nil, 4, "d", "e"},
+		{"Older cached commit", 0, 8, nil, []string{"c"}, 4, "d", "c"},
+		{"Both commits cached", 0, 8, nil, []string{"c", "e"}, 4, "d", "e"},
+		{"Newer commit is broken", 0, 8, map[string]string{"e": "f"}, nil, 4, "d", "c"},
+		{"Run of broken commits", 0, 8, map[string]string{"c": "b", "e": "f", "f": "g"}, nil, 4, "d", "b"},
+		{"Only window ends left", 3, 5, nil, nil, 4, "d", ""},
+		{"Only broken commits left", 2, 6, map[string]string{"c": "b", "e": "f"}, nil, 4, "d", ""},
+		{"Broken replacement", 0, 8, map[string]string{"d": "e"}, nil, 4, "e", "f"},
+		{"Already replaced", 0, 8, map[string]string{"d": "c"}, nil, 4, "d", "c"},
+	}
+
+	for _, v := range values {
+		t.Run(v.name, func(t *testing.T) {
+			backupFile, err := os.CreateTemp(t.TempDir(), "replacements")
+			assert.NoError(t, err, "Failed to create replacements backup")
+			defer backupFile.Close()
+
+			rep := replica{
+				log: logrus.NewEntry(logrus.StandardLogger()),
+				parentJob: &Job{
+					builtImages:                  make(map[string]bool),
+					commitReplacements:           &sync.Map{},
+					commitReplacementsBackupFile: backupFile,
+				},
+			}
+			for commit, replacement := range v.replacements {
+				rep.parentJob.commitReplacements.Store(commit, replacement)
+			}
+			for _, image := range v.built {
+				rep.parentJob.setImageBuilt(rep.parentJob.getDockerImageOfCommit(image))
+			}
+
+			window := commitWindow{
+				commits:          commits,
+				goodCommitOffset: v.goodCommitOffset,
+				badCommitOffset:  v.badCommitOffset,
+			}
+			err = rep.replaceCommit(window, v.commitOffset, v.commitHash, BuildFailed, "broken", "")
+
+			replacement, ok := rep.parentJob.commitReplacements.Load(v.commitHash)
+			if v.expectedReplacement == "" {
+				assert.ErrorAs(t, err, &skippedCommitError{}, "Commit wasn't skipped")
+				assert.False(t, ok, "Commit was replaced")
+				return
+			}
+			assert.NoError(t, err, "Failed to replace commit")
+			assert.True(t, ok, "Commit wasn't replaced")
+			assert.Equal(t, v.expectedReplacement, replacement, "Wrong replacement")
+		})
+	}
+}
+
+func TestReplaceCommitGraph(t *testing.T) {
+	// good -> a -> c -> m -> bad, where m merges b, which branched off of good
+	commits := []string{"good", "a", "b", "c", "m", "bad"}
+	ancestors := [][]int{{}, {}, {}, {1}, {1, 2, 3}, {1, 2, 3, 4}}
+
+	values := []struct {
+		name         string
+		candidates   []int
+		replacements map[string]string
+		commitOffset int
+
+		expectedReplacement string // Empty if the commit should be skipped
+	}{
+		{"Parent", []int{1, 2, 3, 4, 5}, nil, 4, "c"},
+		{"Child", []int{1, 2, 3, 4, 5}, nil, 3, "m"},
+		{"Unrelated commit is skipped over", []int{1, 2, 3, 4, 5}, map[string]string{"m": "c"}, 3, "a"},
+		{"Other parent", []int{1, 2, 3, 4, 5}, map[string]string{"c": "m"}, 4, "b"},
+		{"Grandparent is no replacement", []int{1, 2, 3, 4, 5}, map[string]string{"c": "m", "b": "m"}, 4, ""},
+		{"Parent is no candidate", []int{2, 4, 5}, nil, 4, "b"},
+		{"No related candidate left", []int{2, 3}, nil, 3, ""},
+	}
+
+	for _, v := range values {
+		t.Run(v.name, func(t *testing.T) {
+			backupFile, err := os.CreateTemp(t.TempDir(), "replacements")
+			assert.NoError(t, err, "Failed to create replacements backup")
+			defer backupFile.Close()
+
+			rep := replica{
+				log: logrus.NewEntry(logrus.StandardLogger()),
+				parentJob: &Job{
+					builtImages:                  make(map[string]bool),
+					commitReplacements:           &sync.Map{},
+					commitReplacementsBackupFile: backupFile,
+				},
+			}
+			for i, commitAncestors := range ancestors {
+				rep.parentJob.ancestors = append(rep.parentJob.ancestors, newCommitSet(len(commits)))
+				for _, ancestor := range commitAncestors {
+					rep.parentJob.ancestors[i].add(ancestor)
+				}
+			}
+			for commit, replacement := range v.replacements {
+				rep.parentJob.commitReplacements.Store(commit, replacement)
+			}
+
+			window := commitWindow{
+				commits:          commits,
+				goodCommitOffset: 0,
+				badCommitOffset:  len(commits) - 1,
+				candidates:       newCommitSet(len(commits)),
+			}
+			for _, candidate := range v.candidates {
+				window.candidates.add(candidate)
+			}
+			err = rep.replaceCommit(window, v.commitOffset, commits[v.commitOffset], BuildFailed, "broken", "")
+
+			replacement, ok := rep.parentJob.commitReplacements.Load(commits[v.commitOffset])
+			if v.expectedReplacement == "" {
+				assert.ErrorAs(t, err, &skippedCommitError{}, "Commit wasn't skipped")
+				assert.False(t, ok, "Commit was replaced")
+				return
+			}
+			assert.NoError(t, err, "Failed to replace commit")
+			assert.True(t, ok, "Commit wasn't replaced")
+			assert.Equal(t, v.expectedReplacement, replacement, "Wrong replacement")
+		})
+	}
+}
+
+func TestGetAmbiguousRange(t *testing.T) {
+	commits := []string{"padl", "a", "b", "c", "d", "padr"}
+
+	values := []struct {
+		name             string
+		goodCommitOffset int
+		badCommitOffset  int
+		replacements     map[string]string
+
+		expectedRange *CommitRange
+	}{
+		{"No broken commits", 2, 3, nil, nil},
+		{"Broken commits elsewhere", 2, 3, map[string]string{"d": "padr", "a": "padl"}, nil},
+		{"Bad commit replaced by newer commit", 2, 3, map[string]string{"c": "d"}, &CommitRange{"b", "d", []string{"c", "d"}}},
+		{"Good commit replaced by older commit", 2, 3, map[string]string{"b": "a"}, &CommitRange{"a", "c", []string{"b", "c"}}},
+		{"Run of broken commits", 2, 3, map[string]string{"b": "padl", "a": "padl", "c": "d"}, &CommitRange{"padl", "d", []string{"a", "b", "c", "d"}}},
+		{"Skipped commit", 1, 3, nil, &CommitRange{"a", "c", []string{"b", "c"}}},
+	}
+
+	for _, v := range values {
+		t.Run(v.name, func(t *testing.T) {
+			rep := replica{
+				goodCommitOffset: v.goodCommitOffset,
+				badCommitOffset:  v.badCommitOffset,
+				commits:          commits,
+				parentJob: &Job{
+					commitReplacements: &sync.Map{},
+				},
+			}
+			for commit, replacement := range v.replacements {
+				rep.parentJob.commitReplacements.Store(commit, replacement)
+			}
+
+			assert.Equal(t, v.expectedRange, rep.getAmbiguousRange(), "Wrong ambiguous range")
+		})
 	}
 }
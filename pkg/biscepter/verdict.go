@@ -0,0 +1,162 @@
+package biscepter
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// termRegex matches valid terms, which have to be usable within URL paths
+var termRegex = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// Verdict specifies how a running system was rated
+type Verdict int
+
+const (
+	// The system does not exhibit the issue
+	Good Verdict = iota
+	// The system exhibits the issue
+	Bad
+	// The system cannot be tested
+	Skip
+)
+
+// String returns the lowercase name of the verdict, as used by git bisect
+func (v Verdict) String() string {
+	switch v {
+	case Good:
+		return "good"
+	case Bad:
+		return "bad"
+	case Skip:
+		return "skip"
+	default:
+		return fmt.Sprintf("Verdict(%d)", int(v))
+	}
+}
+
+// MarshalText encodes the verdict as its lowercase name
+func (v Verdict) MarshalText() ([]byte, error) {
+	if v != Good && v != Bad && v != Skip {
+		return nil, fmt.Errorf("invalid verdict %d", int(v))
+	}
+	return []byte(v.String()), nil
+}
+
+// UnmarshalText decodes a verdict from its lowercase name
+func (v *Verdict) UnmarshalText(text []byte) error {
+	for _, verdict := range []Verdict{Good, Bad, Skip} {
+		if string(text) == verdict.String() {
+			*v = verdict
+			return nil
+		}
+	}
+	return fmt.Errorf("%q is not a valid verdict", text)
+}
+
+// A VerdictRecord represents a single verdict received by a replica
+type VerdictRecord struct {
+	Commit  string  `json:"commit"`  // The hash of the rated commit
+	Verdict Verdict `json:"verdict"` // The verdict the commit received
+	Output  string  `json:"output"`  // The combined stdout and stderr of the job's verdict command, if the verdict was determined by it
+
+	Timestamp time.Time `json:"timestamp"` // When the verdict was received
+	Notes     string    `json:"notes"`     // Optional notes about the verdict, as set in the rated RunningSystem
+}
+
+// VerdictFromExitCode maps the exit code of a verdict script to a verdict, matching the semantics of git bisect run.
+// An exit code of 0 means good, 125 means skip and the remaining codes from 1 to 127 mean bad.
+// Any other exit code results in an error, signaling that the bisection should be aborted.
+func VerdictFromExitCode(exitCode int) (Verdict, error) {
+	switch {
+	case exitCode == 0:
+		return Good, nil
+	case exitCode == 125:
+		return Skip, nil
+	case exitCode > 0 && exitCode < 128:
+		return Bad, nil
+	default:
+		return 0, fmt.Errorf("exit code %d is not a valid verdict", exitCode)
+	}
+}
+
+// setTerms sets the default terms of the job if none were set and checks that the terms are valid
+func (j *Job) setTerms() error {
+	oldDefault, newDefault := Good.String(), Bad.String()
+	if j.Reverse {
+		oldDefault, newDefault = newDefault, oldDefault
+	}
+	if j.TermOld == "" {
+		j.TermOld = oldDefault
+	}
+	if j.TermNew == "" {
+		j.TermNew = newDefault
+	}
+
+	if j.TermOld == j.TermNew {
+		return fmt.Errorf("old and new term are both %q", j.TermOld)
+	}
+	for _, term := range []string{j.TermOld, j.TermNew} {
+		if !termRegex.MatchString(term) {
+			return fmt.Errorf("term %q may only contain letters, digits, dashes and underscores", term)
+		}
+		verdict, _ := j.VerdictOfTerm(term)
+		if strings.EqualFold(term, Skip.String()) || (strings.EqualFold(term, Good.String()) && verdict != Good) || (strings.EqualFold(term, Bad.String()) && verdict != Bad) {
+			return fmt.Errorf("term %q contradicts the verdict it stands for", term)
+		}
+	}
+	return nil
+}
+
+// VerdictOfTerm returns the verdict which the passed term stands for, i.e. good for the job's term of the behaviour of the good commit and bad for the other one.
+// Skip is returned for the term "skip".
+// If the term is neither one of the job's terms nor "skip", an error is returned.
+func (j *Job) VerdictOfTerm(term string) (Verdict, error) {
+	switch {
+	case strings.EqualFold(term, j.TermOld):
+		return j.normalizeVerdict(Good), nil
+	case strings.EqualFold(term, j.TermNew):
+		return j.normalizeVerdict(Bad), nil
+	case strings.EqualFold(term, Skip.String()):
+		return Skip, nil
+	default:
+		return 0, fmt.Errorf("%q is neither %q, %q nor %q", term, j.TermOld, j.TermNew, Skip)
+	}
+}
+
+// TermOfVerdict returns the term the passed verdict stands for in this job
+func (j *Job) TermOfVerdict(verdict Verdict) string {
+	switch j.normalizeVerdict(verdict) {
+	case Good:
+		return j.TermOld
+	case Bad:
+		return j.TermNew
+	default:
+		return verdict.String()
+	}
+}
+
+// normalizeVerdict converts between verdicts and their meaning for the bisection, where good stands for the behaviour of the old commit and bad for the behaviour of the new commit.
+// Unless the job is reversed, this is the identity.
+func (j *Job) normalizeVerdict(verdict Verdict) Verdict {
+	if !j.Reverse {
+		return verdict
+	}
+	switch verdict {
+	case Good:
+		return Bad
+	case Bad:
+		return Good
+	default:
+		return verdict
+	}
+}
+
+// getOldAndNewCommits returns the old and new commit of the job, which are the good and bad commit respectively, unless the job is reversed
+func (j *Job) getOldAndNewCommits() (string, string) {
+	if j.Reverse {
+		return j.BadCommit, j.GoodCommit
+	}
+	return j.GoodCommit, j.BadCommit
+}
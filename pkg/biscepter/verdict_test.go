@@ -0,0 +1,120 @@
+package biscepter
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerdictFromExitCode(t *testing.T) {
+	values := []struct {
+		exitCode int
+
+		verdict Verdict
+		err     bool
+	}{
+		{0, Good, false},
+		{1, Bad, false},
+		{124, Bad, false},
+		{125, Skip, false},
+		{126, Bad, false},
+		{127, Bad, false},
+		{128, 0, true},
+		{255, 0, true},
+		{-1, 0, true},
+	}
+
+	for _, v := range values {
+		verdict, err := VerdictFromExitCode(v.exitCode)
+		if v.err {
+			assert.Errorf(t, err, "Exit code %d didn't raise an error", v.exitCode)
+		} else {
+			assert.NoErrorf(t, err, "Exit code %d raised an error", v.exitCode)
+			assert.Equalf(t, v.verdict, verdict, "Wrong verdict for exit code %d", v.exitCode)
+		}
+	}
+}
+
+func TestSetTerms(t *testing.T) {
+	values := []struct {
+		reverse bool
+		termOld string
+		termNew string
+
+		expectedOld string
+		expectedNew string
+		err         bool
+	}{
+		{false, "", "", "good", "bad", false},
+		{true, "", "", "bad", "good", false},
+		{false, "working", "broken", "working", "broken", false},
+		{true, "broken", "fixed", "broken", "fixed", false},
+		{true, "broken", "", "broken", "good", false},
+		{false, "same", "same", "", "", true},
+		{false, "skip", "broken", "", "", true},
+		{false, "bad", "good", "", "", true},
+		{true, "good", "fixed", "", "", true},
+		{false, "not working", "broken", "", "", true},
+	}
+
+	for _, v := range values {
+		job := Job{Reverse: v.reverse, TermOld: v.termOld, TermNew: v.termNew}
+		err := job.setTerms()
+		if v.err {
+			assert.Errorf(t, err, "Terms %q and %q with reverse %t didn't raise an error", v.termOld, v.termNew, v.reverse)
+		} else {
+			assert.NoErrorf(t, err, "Terms %q and %q with reverse %t raised an error", v.termOld, v.termNew, v.reverse)
+			assert.Equal(t, v.expectedOld, job.TermOld, "Wrong old term")
+			assert.Equal(t, v.expectedNew, job.TermNew, "Wrong new term")
+		}
+	}
+}
+
+func TestVerdictOfTerm(t *testing.T) {
+	values := []struct {
+		reverse bool
+		term    string
+
+		verdict Verdict
+		err     bool
+	}{
+		{false, "working", Good, false},
+		{false, "broken", Bad, false},
+		{false, "Broken", Bad, false},
+		{false, "skip", Skip, false},
+		{false, "fixed", 0, true},
+		{true, "working", Bad, false},
+		{true, "broken", Good, false},
+		{true, "skip", Skip, false},
+	}
+
+	for _, v := range values {
+		job := Job{Reverse: v.reverse, TermOld: "working", TermNew: "broken"}
+		verdict, err := job.VerdictOfTerm(v.term)
+		if v.err {
+			assert.Errorf(t, err, "Term %q didn't raise an error", v.term)
+		} else {
+			assert.NoErrorf(t, err, "Term %q raised an error", v.term)
+			assert.Equalf(t, v.verdict, verdict, "Wrong verdict for term %q with reverse %t", v.term, v.reverse)
+			assert.Equalf(t, strings.ToLower(v.term), job.TermOfVerdict(verdict), "Wrong term for verdict %s with reverse %t", verdict, v.reverse)
+		}
+	}
+}
+
+func TestVerdictText(t *testing.T) {
+	for _, verdict := range []Verdict{Good, Bad, Skip} {
+		text, err := verdict.MarshalText()
+		assert.NoErrorf(t, err, "Verdict %s couldn't be marshalled", verdict)
+
+		var unmarshalled Verdict
+		assert.NoErrorf(t, unmarshalled.UnmarshalText(text), "Verdict %s couldn't be unmarshalled", verdict)
+		assert.Equal(t, verdict, unmarshalled, "Verdict changed after marshalling and unmarshalling")
+	}
+
+	_, err := Verdict(42).MarshalText()
+	assert.Error(t, err, "Invalid verdict was marshalled")
+
+	var verdict Verdict
+	assert.Error(t, verdict.UnmarshalText([]byte("maybe")), "Invalid verdict was unmarshalled")
+}
@@ -0,0 +1,235 @@
+package biscepter
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+)
+
+type verificationYaml struct {
+	Runs        int `yaml:"runs" default:"1"`
+	MaxRestarts int `yaml:"maxRestarts" default:"3"`
+}
+
+// VerificationConfig configures the verification of offending commits.
+//
+// Once a replica found its offending commit, it re-tests the offending commit and its predecessor, i.e. the newest commit rated like the old commit,
+// before sending out the [OffendingCommit]. If the re-tests confirm the verdicts of both commits, the offending commit's Status is Verified.
+// If they contradict the previous verdicts, the replica restarts its bisection with the disputed commit window widened, at most MaxRestarts times.
+// Once no restarts are left, the offending commit is sent out with the status Inconsistent.
+type VerificationConfig struct {
+	Runs        int // How many times the offending commit and its predecessor are re-tested each. Defaults to 1
+	MaxRestarts int // How many times the bisection is restarted if the re-tests contradict the previous verdicts. Zero disables restarting
+}
+
+// validate checks whether the config's values are valid and sets the default amount of runs if none was set
+func (c *VerificationConfig) validate() error {
+	if c.Runs == 0 {
+		c.Runs = 1
+	}
+
+	if c.Runs < 0 {
+		return fmt.Errorf("amount of verification runs %d is negative", c.Runs)
+	}
+	if c.MaxRestarts < 0 {
+		return fmt.Errorf("maximum amount of restarts %d is negative", c.MaxRestarts)
+	}
+	return nil
+}
+
+// A VerificationStatus states how certain the offending commit of a replica is, given the verdicts the replica received
+type VerificationStatus int
+
+const (
+	// The offending commit wasn't verified, and no inconsistent verdicts were received. This is the status of all offending commits if the job doesn't verify them
+	Unverified VerificationStatus = iota
+	// Re-testing the offending commit and its predecessor confirmed their verdicts, and no inconsistent verdicts were received
+	Verified
+	// Inconsistent verdicts were received, e.g. a commit newer than a commit rated like the new commit was rated like the old commit,
+	// which means that the issue isn't monotonic or some verdicts were wrong
+	Inconsistent
+)
+
+func (s VerificationStatus) String() string {
+	switch s {
+	case Verified:
+		return "verified"
+	case Inconsistent:
+		return "inconsistent"
+	default:
+		return "unverified"
+	}
+}
+
+// MarshalText encodes the status as its string representation
+func (s VerificationStatus) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// checkConsistency returns whether the passed normalized verdict of the commit with the passed offset is consistent with this replica's current commit window.
+// A commit rated like the new commit may not be older than the newest commit rated like the old commit, and vice versa.
+// Inconsistencies are logged as warnings and reported alongside the offending commit.
+func (r *replica) checkConsistency(commitOffset int, verdict Verdict) bool {
+	if r.posterior != nil {
+		// Probabilistic bisection expects wrong verdicts
+		return true
+	}
+
+	var inconsistency string
+	switch {
+	case r.candidates != nil:
+		if verdict == Good && (commitOffset == r.badCommitOffset || r.parentJob.ancestors[commitOffset].has(r.badCommitOffset)) {
+			inconsistency = fmt.Sprintf("commit %s was rated %s, but is a descendant of %s commit %s", r.commits[commitOffset], r.parentJob.TermOld, r.parentJob.TermNew, r.commits[r.badCommitOffset])
+		}
+	case verdict == Good && commitOffset >= r.badCommitOffset:
+		inconsistency = fmt.Sprintf("commit %s was rated %s, but isn't older than %s commit %s", r.commits[commitOffset], r.parentJob.TermOld, r.parentJob.TermNew, r.commits[r.badCommitOffset])
+	case verdict == Bad && commitOffset <= r.goodCommitOffset:
+		inconsistency = fmt.Sprintf("commit %s was rated %s, but isn't newer than %s commit %s", r.commits[commitOffset], r.parentJob.TermNew, r.parentJob.TermOld, r.commits[r.goodCommitOffset])
+	}
+	if inconsistency == "" {
+		return true
+	}
+
+	r.log.Warnf("Inconsistent verdict, the issue might not be monotonic: %s", inconsistency)
+	r.inconsistencies = append(r.inconsistencies, inconsistency)
+	return false
+}
+
+// verify sets the status of the passed offending commit and starts its verification if the job verifies offending commits.
+// If the offending commit is verified, nil is returned and the offending commit is returned by finishVerification once all re-tests were rated instead.
+func (r *replica) verify(oc *OffendingCommit) *OffendingCommit {
+	oc.Inconsistencies = r.inconsistencies
+	if len(r.inconsistencies) != 0 {
+		oc.Status = Inconsistent
+	}
+	if r.parentJob.Verification == nil {
+		return oc
+	}
+
+	predecessorOffset := r.getPredecessorOffset()
+	r.offendingCommit = oc
+	r.verificationVerdicts = map[int][]Verdict{
+		predecessorOffset: nil,
+		r.badCommitOffset: nil,
+	}
+	r.log.Infof("Verifying offending commit %s by re-testing it and commit %s %d times", r.commits[r.badCommitOffset], r.commits[predecessorOffset], r.parentJob.Verification.Runs)
+	return nil
+}
+
+// getPredecessorOffset returns the offset of the commit the offending commit is compared against when verifying it.
+// This is the current good commit, or the offending commit's first parent if the full history is bisected and the first parent is among the commits.
+func (r replica) getPredecessorOffset() int {
+	if r.candidates != nil {
+		if firstParent, err := getFirstParent(r.commits[r.badCommitOffset], r.repoPath); err == nil {
+			if commitOffset := slices.Index(r.commits, firstParent); commitOffset != -1 {
+				return commitOffset
+			}
+		}
+	}
+	return r.goodCommitOffset
+}
+
+// getVerificationCommits returns the offsets of at most count commits which still have to be re-tested to verify the offending commit
+func (r replica) getVerificationCommits(count int) []int {
+	commitOffsets := []int{}
+	for commitOffset, verdicts := range r.verificationVerdicts {
+		if len(verdicts) < r.parentJob.Verification.Runs {
+			commitOffsets = append(commitOffsets, commitOffset)
+		}
+	}
+	sort.Ints(commitOffsets)
+	return commitOffsets[:min(max(count, 1), len(commitOffsets))]
+}
+
+// addVerificationVerdict records the passed normalized verdict of a re-test of the commit with the passed offset
+func (r *replica) addVerificationVerdict(commitOffset int, verdict Verdict) {
+	if verdicts, ok := r.verificationVerdicts[commitOffset]; ok && len(verdicts) < r.parentJob.Verification.Runs {
+		r.verificationVerdicts[commitOffset] = append(verdicts, verdict)
+	}
+}
+
+// finishVerification returns the offending commit which is being verified, with its status set, once all re-tests were rated.
+// If the re-tests contradict the previous verdicts and the replica may still restart, the bisection is restarted with the disputed commit window widened instead.
+// Otherwise, nil is returned.
+func (r *replica) finishVerification() *OffendingCommit {
+	if len(r.getVerificationCommits(len(r.verificationVerdicts))) != 0 {
+		return nil
+	}
+
+	oc, verificationVerdicts := r.offendingCommit, r.verificationVerdicts
+	r.offendingCommit, r.verificationVerdicts = nil, nil
+
+	confirmed := true
+	disputed := make(map[int]bool)
+	inconsistencies := []string{}
+	for commitOffset, verdicts := range verificationVerdicts {
+		expectedVerdict := Good
+		if commitOffset == r.badCommitOffset {
+			expectedVerdict = Bad
+		}
+		for _, verdict := range verdicts {
+			if verdict == Skip {
+				confirmed = false
+			} else if verdict != expectedVerdict {
+				confirmed = false
+				disputed[commitOffset] = true
+				inconsistency := fmt.Sprintf("commit %s was rated %s when verifying the offending commit, but %s before", r.commits[commitOffset], r.parentJob.TermOfVerdict(r.parentJob.normalizeVerdict(verdict)), r.parentJob.TermOfVerdict(r.parentJob.normalizeVerdict(expectedVerdict)))
+				r.log.Warnf("Inconsistent verdict, the issue might not be monotonic: %s", inconsistency)
+				inconsistencies = append(inconsistencies, inconsistency)
+			}
+		}
+	}
+
+	if len(disputed) != 0 && r.restarts < r.parentJob.Verification.MaxRestarts {
+		r.restart(disputed[r.badCommitOffset], len(disputed) > 1 || !disputed[r.badCommitOffset], verificationVerdicts)
+		return nil
+	}
+	r.inconsistencies = append(r.inconsistencies, inconsistencies...)
+
+	oc.Verdicts = r.verdicts
+	oc.Inconsistencies = r.inconsistencies
+	oc.Restarts = r.restarts
+	if len(r.inconsistencies) != 0 {
+		oc.Status = Inconsistent
+	} else if confirmed {
+		oc.Status = Verified
+	}
+	r.log.Infof("Verification of offending commit %s finished with status %s", oc.Commit, oc.Status)
+	return oc
+}
+
+// restart restarts the bisection of this replica after verifying its offending commit failed, widening the commit window on the disputed sides.
+// If the offending commit is disputed, the bad commit is moved towards the newest commit, and if its predecessor is disputed, the good commit is moved towards the oldest commit.
+// The window is widened by its current size times 2^restarts, so that repeated failures quickly cover the whole history.
+// If the full history is bisected, the bisection starts over with all commits as candidates instead, and if the job bisects probabilistically,
+// the verdicts of the re-tests are applied to the posterior and the window, which was narrowed down to the offending commit, spans all commits again.
+// Inconsistencies and possible other offending commits found so far are discarded, as the restarted bisection supersedes them.
+func (r *replica) restart(offendingDisputed, predecessorDisputed bool, verificationVerdicts map[int][]Verdict) {
+	r.restarts++
+	r.inconsistencies = nil
+	r.possibleOtherCommits = nil
+
+	switch {
+	case r.posterior != nil:
+		for commitOffset, verdicts := range verificationVerdicts {
+			for _, verdict := range verdicts {
+				r.updatePosterior(commitOffset, verdict)
+			}
+		}
+		r.goodCommitOffset, r.badCommitOffset = 0, len(r.commits)-1
+	case r.candidates != nil:
+		r.initCandidates()
+		r.badCommitOffset = len(r.commits) - 1
+	default:
+		width := max(r.badCommitOffset-r.goodCommitOffset, 1) << r.restarts
+		if predecessorDisputed {
+			r.goodCommitOffset = max(r.goodCommitOffset-width, 0)
+		}
+		if offendingDisputed {
+			r.badCommitOffset = min(r.badCommitOffset+width, len(r.commits)-1)
+		}
+	}
+
+	r.log.Warnf("Verification of the offending commit failed, restarting the bisection (restart %d of %d) with the remaining window from commit %d to %d", r.restarts, r.parentJob.Verification.MaxRestarts, r.goodCommitOffset, r.badCommitOffset)
+	r.saveCheckpoint()
+}
@@ -0,0 +1,221 @@
+package biscepter
+
+import (
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckConsistency(t *testing.T) {
+	values := []struct {
+		commitOffset int
+		verdict      Verdict
+		consistent   bool
+	}{
+		{3, Good, true},
+		{3, Bad, true},
+		{5, Good, false},
+		{6, Good, false},
+		{6, Bad, true},
+		{2, Bad, false},
+		{1, Bad, false},
+		{1, Good, true},
+		{4, Skip, true},
+	}
+
+	for _, v := range values {
+		rep := &replica{
+			goodCommitOffset: 2,
+			badCommitOffset:  5,
+			commits:          []string{"a", "b", "c", "d", "e", "f", "g"},
+			log:              logrus.NewEntry(logrus.StandardLogger()),
+			parentJob:        &Job{TermOld: "good", TermNew: "bad"},
+		}
+		assert.Equalf(t, v.consistent, rep.checkConsistency(v.commitOffset, v.verdict), "Wrong consistency of verdict %s of commit %d", v.verdict, v.commitOffset)
+		assert.Equalf(t, v.consistent, len(rep.inconsistencies) == 0, "Wrong inconsistencies recorded for verdict %s of commit %d", v.verdict, v.commitOffset)
+	}
+
+	t.Run("Full history", func(t *testing.T) {
+		rep := newGraphReplica()
+		rep.parentJob.TermOld, rep.parentJob.TermNew = "good", "bad"
+		rep.applyVerdict(3, Bad)
+		assert.False(t, rep.checkConsistency(5, Good), "Descendant of bad commit rated good is consistent")
+		assert.True(t, rep.checkConsistency(2, Good), "Commit on other branch rated good is inconsistent")
+	})
+}
+
+func TestVerification(t *testing.T) {
+	repoPath, commits := createTestRepo(t, []string{"a", "b", "c", "d"})
+
+	newReplica := func(verification *VerificationConfig) *replica {
+		return &replica{
+			parentJob: &Job{
+				TermOld:            "good",
+				TermNew:            "bad",
+				Verification:       verification,
+				Checkpoint:         os.DevNull,
+				commitReplacements: &sync.Map{},
+			},
+			repoPath:         repoPath,
+			commits:          commits,
+			goodCommitOffset: 1,
+			badCommitOffset:  2,
+			skippedCommits:   make(map[int]bool),
+			log:              logrus.NewEntry(logrus.StandardLogger()),
+		}
+	}
+
+	t.Run("Without verification", func(t *testing.T) {
+		rep := newReplica(nil)
+		oc := rep.getOffendingCommit()
+		assert.NotNil(t, oc, "Offending commit wasn't reported")
+		assert.Equal(t, Unverified, oc.Status, "Wrong status")
+	})
+
+	t.Run("Inconsistent verdicts without verification", func(t *testing.T) {
+		rep := newReplica(nil)
+		rep.checkConsistency(3, Good)
+		oc := rep.getOffendingCommit()
+		assert.Equal(t, Inconsistent, oc.Status, "Wrong status")
+		assert.Len(t, oc.Inconsistencies, 1, "Wrong amount of inconsistencies")
+	})
+
+	values := []struct {
+		name     string
+		verdicts map[int][]Verdict
+		status   VerificationStatus
+	}{
+		{"Verified", map[int][]Verdict{1: {Good, Good}, 2: {Bad, Bad}}, Verified},
+		{"Predecessor rated bad", map[int][]Verdict{1: {Good, Bad}, 2: {Bad, Bad}}, Inconsistent},
+		{"Offending commit rated good", map[int][]Verdict{1: {Good, Good}, 2: {Good, Bad}}, Inconsistent},
+		{"Skipped", map[int][]Verdict{1: {Good, Skip}, 2: {Bad, Bad}}, Unverified},
+	}
+
+	for _, v := range values {
+		t.Run(v.name, func(t *testing.T) {
+			rep := newReplica(&VerificationConfig{Runs: 2})
+			assert.Nil(t, rep.getOffendingCommit(), "Offending commit was reported before verifying it")
+			assert.Equal(t, []int{1, 2}, rep.getNextCommits(2), "Wrong commits re-tested")
+			assert.Equal(t, []int{1}, rep.getNextCommits(1), "Wrong commits re-tested")
+			assert.True(t, rep.isRelevant(1), "Predecessor is irrelevant")
+			assert.False(t, rep.isRelevant(3), "Commit newer than the offending commit is relevant")
+
+			for i := range 2 {
+				assert.Nil(t, rep.getOffendingCommit(), "Offending commit was reported before verifying it")
+				for commitOffset, verdicts := range v.verdicts {
+					rep.addVerificationVerdict(commitOffset, verdicts[i])
+				}
+			}
+
+			oc := rep.getOffendingCommit()
+			assert.NotNil(t, oc, "Offending commit wasn't reported after verifying it")
+			assert.Equal(t, commits[2], oc.Commit, "Wrong offending commit")
+			assert.Equal(t, v.status, oc.Status, "Wrong status")
+			assert.Equal(t, v.status == Inconsistent, len(oc.Inconsistencies) != 0, "Wrong inconsistencies")
+			assert.Nil(t, rep.offendingCommit, "Verification wasn't finished")
+		})
+	}
+}
+
+func TestVerificationRestart(t *testing.T) {
+	values := []struct {
+		name         string
+		verdicts     map[int]Verdict
+		goodOffset   int
+		badOffset    int
+		restarts     int
+		inconsistent bool
+	}{
+		{"Offending commit disputed", map[int]Verdict{3: Good, 4: Good}, 3, 6, 1, false},
+		{"Predecessor disputed", map[int]Verdict{3: Bad, 4: Bad}, 1, 4, 1, false},
+		{"Both disputed", map[int]Verdict{3: Bad, 4: Good}, 1, 6, 1, false},
+		{"No restarts left", map[int]Verdict{3: Bad, 4: Bad}, 3, 4, 0, true},
+	}
+
+	for _, v := range values {
+		t.Run(v.name, func(t *testing.T) {
+			maxRestarts := 1
+			if v.inconsistent {
+				maxRestarts = 0
+			}
+			rep := &replica{
+				parentJob: &Job{
+					TermOld:      "good",
+					TermNew:      "bad",
+					Verification: &VerificationConfig{Runs: 1, MaxRestarts: maxRestarts},
+					Checkpoint:   os.DevNull,
+				},
+				commits:              []string{"a", "b", "c", "d", "e", "f", "g"},
+				goodCommitOffset:     3,
+				badCommitOffset:      4,
+				skippedCommits:       make(map[int]bool),
+				inconsistencies:      []string{"earlier inconsistency"},
+				possibleOtherCommits: []string{"c"},
+				log:                  logrus.NewEntry(logrus.StandardLogger()),
+			}
+			rep.verify(&OffendingCommit{Commit: "e", CommitOffset: 4})
+			for commitOffset, verdict := range v.verdicts {
+				rep.addVerificationVerdict(commitOffset, verdict)
+			}
+
+			oc := rep.finishVerification()
+			assert.Equal(t, v.inconsistent, oc != nil, "Offending commit reported despite failed verification")
+			assert.Equal(t, v.goodOffset, rep.goodCommitOffset, "Wrong good commit after restart")
+			assert.Equal(t, v.badOffset, rep.badCommitOffset, "Wrong bad commit after restart")
+			assert.Equal(t, v.restarts, rep.restarts, "Wrong amount of restarts")
+			if oc != nil {
+				assert.Equal(t, Inconsistent, oc.Status, "Wrong status")
+				assert.Len(t, oc.Inconsistencies, 2, "Wrong amount of inconsistencies")
+			} else {
+				assert.Empty(t, rep.inconsistencies, "Inconsistencies weren't discarded on restart")
+				assert.Empty(t, rep.possibleOtherCommits, "Possible other commits weren't discarded on restart")
+				assert.Nil(t, rep.offendingCommit, "Verification wasn't finished")
+			}
+		})
+	}
+
+	t.Run("Full history", func(t *testing.T) {
+		rep := newGraphReplica()
+		rep.parentJob.TermOld, rep.parentJob.TermNew = "good", "bad"
+		rep.parentJob.Verification = &VerificationConfig{Runs: 1, MaxRestarts: 1}
+		rep.parentJob.Checkpoint = os.DevNull
+		rep.applyVerdict(3, Bad)
+		rep.verify(&OffendingCommit{})
+		for commitOffset := range rep.verificationVerdicts {
+			rep.addVerificationVerdict(commitOffset, Good)
+		}
+		assert.Nil(t, rep.finishVerification(), "Offending commit reported despite failed verification")
+		assert.Equal(t, len(rep.commits)-1, rep.badCommitOffset, "Bisection wasn't restarted")
+		assert.Equal(t, len(rep.commits)-1, rep.candidates.count(), "Bisection wasn't restarted")
+	})
+
+	t.Run("Probabilistic", func(t *testing.T) {
+		rep := &replica{
+			parentJob: &Job{
+				TermOld:       "good",
+				TermNew:       "bad",
+				Probabilistic: &ProbabilisticConfig{FalseGoodRate: 0.1, Confidence: 0.95},
+				Verification:  &VerificationConfig{Runs: 1, MaxRestarts: 1},
+				Checkpoint:    os.DevNull,
+			},
+			commits: []string{"a", "b", "c", "d", "e", "f", "g"},
+			// Narrowed down to the offending commit once it was found
+			goodCommitOffset: 3,
+			badCommitOffset:  4,
+			skippedCommits:   make(map[int]bool),
+			log:              logrus.NewEntry(logrus.StandardLogger()),
+		}
+		rep.initPosterior()
+		rep.verify(&OffendingCommit{Commit: "e", CommitOffset: 4})
+		rep.addVerificationVerdict(3, Bad)
+		rep.addVerificationVerdict(4, Good)
+
+		assert.Nil(t, rep.finishVerification(), "Offending commit reported despite failed verification")
+		assert.Equal(t, 0, rep.goodCommitOffset, "Window wasn't reset on restart")
+		assert.Equal(t, len(rep.commits)-1, rep.badCommitOffset, "Window wasn't reset on restart")
+		assert.Nil(t, rep.getOffendingCommit(), "Offending commit reported again right after restarting")
+	})
+}